@@ -1,6 +1,7 @@
 package base
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
@@ -11,6 +12,8 @@ import (
 type Stream interface { // todo, make it a bit more streamable, so receive wanted amount of bytes with guaranted amount or timeout or error...
 	Close() error
 	Open() error
+	// like Open, but dialing can be canceled by the context and its deadline is set to the stream (SetDeadline), so it bounds also negotiation of upper layers
+	OpenContext(ctx context.Context) error
 	Disconnect() error // hard end of connection without solving any unassociation or so
 	SetLogger(logger *zap.SugaredLogger)
 	SetDeadline(t time.Time)     // zero time means no deadline
@@ -0,0 +1,103 @@
+package base
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// splits every write into transport writes of at most max bytes, for gateways dropping oversized writes, reads are untouched
+type chunked struct {
+	inner Stream
+	max   int
+
+	redactions []Redaction // of the next write only
+}
+
+func NewChunkedStream(inner Stream, maxChunk int) Stream {
+	return &chunked{
+		inner: inner,
+		max:   maxChunk,
+	}
+}
+
+func (c *chunked) Close() error {
+	return c.inner.Close()
+}
+
+func (c *chunked) Open() error {
+	return c.inner.Open()
+}
+
+func (c *chunked) OpenContext(ctx context.Context) error {
+	return c.inner.OpenContext(ctx)
+}
+
+func (c *chunked) Disconnect() error {
+	return c.inner.Disconnect()
+}
+
+func (c *chunked) SetLogger(logger *zap.SugaredLogger) {
+	c.inner.SetLogger(logger)
+}
+
+func (c *chunked) SetDeadline(d time.Time) {
+	c.inner.SetDeadline(d)
+}
+
+func (c *chunked) SetTimeout(d time.Duration) {
+	c.inner.SetTimeout(d)
+}
+
+func (c *chunked) SetMaxReceivedBytes(m int64) {
+	c.inner.SetMaxReceivedBytes(m)
+}
+
+func (c *chunked) Read(p []byte) (n int, err error) {
+	return c.inner.Read(p)
+}
+
+func (c *chunked) Write(src []byte) error {
+	red := c.redactions
+	c.redactions = nil
+	if c.max <= 0 { // no limit
+		RedactNextWrite(c.inner, red)
+		return c.inner.Write(src)
+	}
+	off := 0
+	for len(src) > c.max {
+		RedactNextWrite(c.inner, ShiftRedactions(red, off, c.max, 0))
+		if err := c.inner.Write(src[:c.max]); err != nil {
+			return err
+		}
+		off += c.max
+		src = src[c.max:]
+	}
+	RedactNextWrite(c.inner, ShiftRedactions(red, off, len(src), 0))
+	return c.inner.Write(src)
+}
+
+func (c *chunked) GetRxTxBytes() (int64, int64) {
+	return c.inner.GetRxTxBytes()
+}
+
+func (c *chunked) OverheadBytes() int {
+	return OverheadBytes(c.inner)
+}
+
+func (c *chunked) Flush() error {
+	return Flush(c.inner)
+}
+
+func (c *chunked) Drain(d time.Duration) error {
+	return Drain(c.inner, d)
+}
+
+func (c *chunked) RedactNextWrite(r []Redaction) {
+	c.redactions = r
+}
+
+func (c *chunked) ConnectionInfo() map[string]string {
+	return ConnectionInfo(c.inner)
+}
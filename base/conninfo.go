@@ -0,0 +1,17 @@
+package base
+
+// implemented by layers with something to say about the connection, result includes lower layers
+type ConnectionInformer interface {
+	ConnectionInfo() map[string]string
+}
+
+// metadata of the whole stack for logs and dashboards, keys are prefixed with layer name (tcp.remote, hdlc.client, serial.baudrate...),
+// never nil, layers without metadata are skipped, so empty map for plain streams
+func ConnectionInfo(transport Stream) map[string]string {
+	if c, ok := transport.(ConnectionInformer); ok {
+		if ret := c.ConnectionInfo(); ret != nil {
+			return ret
+		}
+	}
+	return make(map[string]string)
+}
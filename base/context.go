@@ -0,0 +1,15 @@
+package base
+
+import "context"
+
+// common OpenContext of layered streams, lower stream is opened with the context (cancellable dial, deadline of the context is set to the stream)
+// and then the layer runs its own Open, which finds the lower stream already opened
+func OpenLayer(ctx context.Context, lower Stream, open func() error) error {
+	if err := lower.OpenContext(ctx); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return open()
+}
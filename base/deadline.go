@@ -0,0 +1,29 @@
+package base
+
+import (
+	"io"
+	"time"
+)
+
+type deadlinereader struct {
+	r           io.Reader
+	setDeadline func(time.Time)
+	timeout     time.Duration
+}
+
+// sets deadline (usually Stream.SetDeadline of the underlying transport) before every read,
+// so io.ReadAll or io.Copy over streaming readers cant block forever
+func NewDeadlineReader(r io.Reader, setDeadline func(time.Time), timeout time.Duration) io.Reader {
+	return &deadlinereader{
+		r:           r,
+		setDeadline: setDeadline,
+		timeout:     timeout,
+	}
+}
+
+func (d *deadlinereader) Read(p []byte) (n int, err error) {
+	if d.setDeadline != nil && d.timeout > 0 {
+		d.setDeadline(time.Now().Add(d.timeout))
+	}
+	return d.r.Read(p)
+}
@@ -0,0 +1,34 @@
+package base
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// implemented by layers which cant be just read till timeout (hdlc would retransmit its last frame), the result includes lower layers
+type Drainer interface {
+	Drain(t time.Duration) error
+}
+
+// throws away whatever comes during t, eof is just the end of a frame (hdlc, wrapper), so reading continues after it,
+// timeout is the expected end, deadline of the stream is cleared at the end
+func Drain(transport Stream, t time.Duration) error {
+	if d, ok := transport.(Drainer); ok {
+		return d.Drain(t)
+	}
+	var buf [256]byte
+	deadline := time.Now().Add(t)
+	transport.SetDeadline(deadline)
+	defer transport.SetDeadline(time.Time{})
+	for time.Now().Before(deadline) {
+		_, err := transport.Read(buf[:])
+		if err != nil && err != io.EOF {
+			if errors.Is(err, ErrCommunicationTimeout) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
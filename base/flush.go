@@ -0,0 +1,16 @@
+package base
+
+// implemented by layers holding back the end of written data till the answer is read (hdlc keeps its last I frame),
+// the result includes lower layers
+type Flusher interface {
+	Flush() error
+}
+
+// pushes everything written so far to the wire, needed for requests without any answer,
+// streams writing everything immediately have nothing to do
+func Flush(transport Stream) error {
+	if f, ok := transport.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
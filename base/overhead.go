@@ -0,0 +1,15 @@
+package base
+
+// implemented by layers which add framing, the result includes lower layers
+type Overheader interface {
+	OverheadBytes() int
+}
+
+// bytes the stack adds to a single apdu on the wire, for hdlc it is per frame, so segmented apdu pays it for every segment,
+// layers without framing (tcp, serial) add nothing, escaping (rfc2217 0xff doubling) isnt counted as it depends on data
+func OverheadBytes(transport Stream) int {
+	if o, ok := transport.(Overheader); ok {
+		return o.OverheadBytes()
+	}
+	return 0
+}
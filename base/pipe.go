@@ -0,0 +1,207 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// one direction of the pipe, writes never block
+type pipebuffer struct {
+	mutex  sync.Mutex
+	data   []byte
+	closed bool
+	notify chan struct{}
+}
+
+type pipeend struct {
+	in              *pipebuffer
+	out             *pipebuffer
+	logger          *zap.SugaredLogger
+	isopen          bool
+	timeout         time.Duration
+	deadline        time.Time
+	totalincoming   int64
+	totaloutgoing   int64
+	currentincoming int64
+	maxincoming     int64
+
+	redactions []Redaction // of the next write only
+}
+
+// two connected in memory streams for in process tests, what is written to one is read from the other,
+// both ends have to be opened, Disconnect of any end closes the pipe for good
+func Pipe() (client Stream, server Stream) {
+	a := &pipebuffer{notify: make(chan struct{}, 1)}
+	b := &pipebuffer{notify: make(chan struct{}, 1)}
+	return &pipeend{in: a, out: b}, &pipeend{in: b, out: a}
+}
+
+func (b *pipebuffer) close() {
+	b.mutex.Lock()
+	b.closed = true
+	b.mutex.Unlock()
+	b.signal()
+}
+
+func (b *pipebuffer) signal() {
+	select {
+	case b.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (p *pipeend) logf(format string, v ...any) {
+	if p.logger != nil {
+		p.logger.Infof(format, v...)
+	}
+}
+
+func (p *pipeend) Close() error {
+	return nil
+}
+
+func (p *pipeend) Open() error {
+	if p.isopen {
+		return nil
+	}
+	p.in.mutex.Lock()
+	closed := p.in.closed
+	p.in.mutex.Unlock()
+	if closed {
+		return fmt.Errorf("pipe is closed")
+	}
+	p.isopen = true
+	return nil
+}
+
+func (p *pipeend) OpenContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d, ok := ctx.Deadline(); ok {
+		p.deadline = d
+	}
+	return p.Open()
+}
+
+func (p *pipeend) Disconnect() error {
+	if p.isopen {
+		p.isopen = false
+		p.in.close()
+		p.out.close()
+		p.logf("Pipe closed, total bytes incoming: %v, outgoing: %v", p.totalincoming, p.totaloutgoing)
+	}
+	return nil
+}
+
+func (p *pipeend) SetLogger(logger *zap.SugaredLogger) {
+	p.logger = logger
+}
+
+func (p *pipeend) SetDeadline(t time.Time) {
+	p.deadline = t
+}
+
+func (p *pipeend) SetTimeout(t time.Duration) {
+	p.timeout = t
+}
+
+func (p *pipeend) SetMaxReceivedBytes(m int64) {
+	p.currentincoming = 0
+	p.maxincoming = m
+}
+
+// the same rules as tcp, the earlier of timeout and deadline, zero time means wait forever
+func (p *pipeend) commdeadline() time.Time {
+	var d time.Time
+	if p.timeout > 0 {
+		d = time.Now().Add(p.timeout)
+	}
+	if !p.deadline.IsZero() && (d.IsZero() || p.deadline.Before(d)) {
+		d = p.deadline
+	}
+	return d
+}
+
+func (p *pipeend) Read(b []byte) (int, error) {
+	if !p.isopen {
+		return 0, ErrNotOpened
+	}
+	if len(b) == 0 {
+		return 0, ErrNothingToRead
+	}
+
+	dl := p.commdeadline()
+	for {
+		p.in.mutex.Lock()
+		if len(p.in.data) > 0 {
+			n := copy(b, p.in.data)
+			p.in.data = p.in.data[n:]
+			p.in.mutex.Unlock()
+			p.totalincoming += int64(n)
+			p.currentincoming += int64(n)
+			if p.maxincoming > 0 && p.currentincoming > p.maxincoming {
+				return 0, fmt.Errorf("received more than allowed")
+			}
+			if p.logger != nil {
+				p.logger.Debugf(LogHex("RX", b[:n]))
+			}
+			return n, nil
+		}
+		closed := p.in.closed
+		p.in.mutex.Unlock()
+		if closed {
+			return 0, io.EOF
+		}
+
+		if dl.IsZero() {
+			<-p.in.notify
+			continue
+		}
+		wait := time.Until(dl)
+		if wait <= 0 {
+			return 0, ErrCommunicationTimeout
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-p.in.notify:
+			timer.Stop()
+		case <-timer.C:
+			return 0, ErrCommunicationTimeout
+		}
+	}
+}
+
+func (p *pipeend) RedactNextWrite(r []Redaction) {
+	p.redactions = r
+}
+
+func (p *pipeend) Write(src []byte) error {
+	if !p.isopen {
+		return ErrNotOpened
+	}
+	red := p.redactions
+	p.redactions = nil
+	p.out.mutex.Lock()
+	if p.out.closed {
+		p.out.mutex.Unlock()
+		return fmt.Errorf("write failed: pipe is closed")
+	}
+	if p.logger != nil {
+		p.logger.Debugf(LogHexRedacted("TX", src, red))
+	}
+	p.totaloutgoing += int64(len(src))
+	p.out.data = append(p.out.data, src...)
+	p.out.mutex.Unlock()
+	p.out.signal()
+	return nil
+}
+
+func (p *pipeend) GetRxTxBytes() (int64, int64) {
+	return p.totalincoming, p.totaloutgoing
+}
@@ -0,0 +1,143 @@
+package base
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// token bucket shared across streams, so multiple connections can share one airtime budget
+type RateLimiter struct {
+	mutex    sync.Mutex
+	rate     float64 // bytes per second
+	burst    float64
+	tokens   float64
+	lastfill time.Time
+}
+
+type ratelimited struct {
+	inner   Stream
+	limiter *RateLimiter
+}
+
+func NewRateLimiter(bytesPerSec int) *RateLimiter {
+	if bytesPerSec <= 0 {
+		bytesPerSec = 1
+	}
+	return &RateLimiter{
+		rate:     float64(bytesPerSec),
+		burst:    float64(bytesPerSec), // one second worth of data
+		tokens:   float64(bytesPerSec),
+		lastfill: time.Now(),
+	}
+}
+
+// wait until n bytes can be spent, bigger chunks than burst are spent in parts
+func (r *RateLimiter) Wait(n int) {
+	for n > 0 {
+		chunk := n
+		if float64(chunk) > r.burst {
+			chunk = int(r.burst)
+		}
+		r.mutex.Lock()
+		now := time.Now()
+		r.tokens += now.Sub(r.lastfill).Seconds() * r.rate
+		if r.tokens > r.burst {
+			r.tokens = r.burst
+		}
+		r.lastfill = now
+		r.tokens -= float64(chunk)
+		var sleep time.Duration
+		if r.tokens < 0 {
+			sleep = time.Duration(-r.tokens / r.rate * float64(time.Second))
+		}
+		r.mutex.Unlock()
+		if sleep > 0 {
+			time.Sleep(sleep)
+		}
+		n -= chunk
+	}
+}
+
+func NewRateLimitedStream(inner Stream, bytesPerSec int) Stream {
+	return NewRateLimitedStreamShared(inner, NewRateLimiter(bytesPerSec))
+}
+
+// use the same limiter for all streams which should share the budget
+func NewRateLimitedStreamShared(inner Stream, limiter *RateLimiter) Stream {
+	return &ratelimited{
+		inner:   inner,
+		limiter: limiter,
+	}
+}
+
+func (r *ratelimited) Close() error {
+	return r.inner.Close()
+}
+
+func (r *ratelimited) Open() error {
+	return r.inner.Open()
+}
+
+func (r *ratelimited) OpenContext(ctx context.Context) error {
+	return r.inner.OpenContext(ctx)
+}
+
+func (r *ratelimited) Disconnect() error {
+	return r.inner.Disconnect()
+}
+
+func (r *ratelimited) SetLogger(logger *zap.SugaredLogger) {
+	r.inner.SetLogger(logger)
+}
+
+func (r *ratelimited) SetDeadline(t time.Time) {
+	r.inner.SetDeadline(t)
+}
+
+func (r *ratelimited) SetTimeout(t time.Duration) {
+	r.inner.SetTimeout(t)
+}
+
+func (r *ratelimited) SetMaxReceivedBytes(m int64) {
+	r.inner.SetMaxReceivedBytes(m)
+}
+
+func (r *ratelimited) Read(p []byte) (n int, err error) {
+	n, err = r.inner.Read(p)
+	if n > 0 {
+		r.limiter.Wait(n) // received bytes are already here, so just hold them back
+	}
+	return
+}
+
+func (r *ratelimited) Write(src []byte) error {
+	r.limiter.Wait(len(src))
+	return r.inner.Write(src)
+}
+
+func (r *ratelimited) GetRxTxBytes() (int64, int64) {
+	return r.inner.GetRxTxBytes()
+}
+
+func (r *ratelimited) OverheadBytes() int {
+	return OverheadBytes(r.inner)
+}
+
+func (r *ratelimited) Flush() error {
+	return Flush(r.inner)
+}
+
+func (r *ratelimited) Drain(d time.Duration) error {
+	return Drain(r.inner, d)
+}
+
+func (r *ratelimited) RedactNextWrite(red []Redaction) {
+	RedactNextWrite(r.inner, red)
+}
+
+func (r *ratelimited) ConnectionInfo() map[string]string {
+	return ConnectionInfo(r.inner)
+}
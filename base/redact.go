@@ -0,0 +1,62 @@
+package base
+
+import "sync/atomic"
+
+// package wide switch, marked ranges of written data (password in aarq...) are zeroed in hex logs of all layers when on
+var redacton atomic.Bool
+
+func SetRedactSecrets(on bool) {
+	redacton.Store(on)
+}
+
+// byte range of written data hidden in hex logs
+type Redaction struct {
+	Offset int
+	Length int
+}
+
+// implemented by layers which log written data or pass it lower, ranges are relative to data of the very next Write,
+// framing layers move them by own header, splitting layers cut them per piece, the lowest layer zeroes them in its hex log
+type Redacter interface {
+	RedactNextWrite(r []Redaction)
+}
+
+func RedactNextWrite(transport Stream, r []Redaction) {
+	if len(r) == 0 {
+		return
+	}
+	if rr, ok := transport.(Redacter); ok {
+		rr.RedactNextWrite(r)
+	}
+}
+
+// part of r inside [from, from+n), relative to from and moved by shift, for layers which frame or split written data
+func ShiftRedactions(r []Redaction, from int, n int, shift int) []Redaction {
+	var ret []Redaction
+	for _, x := range r {
+		s := max(x.Offset, from)
+		e := min(x.Offset+x.Length, from+n)
+		if s < e {
+			ret = append(ret, Redaction{Offset: s - from + shift, Length: e - s})
+		}
+	}
+	return ret
+}
+
+// LogHex with zeroed ranges, redaction has to be switched on by SetRedactSecrets
+func LogHexRedacted(s string, b []byte, r []Redaction) string {
+	if len(r) == 0 || !redacton.Load() {
+		return LogHex(s, b)
+	}
+	c := make([]byte, len(b))
+	copy(c, b)
+	redactbytes(c, r)
+	return LogHex(s, c)
+}
+
+// zeroes ranges in place, parts outside b are ignored
+func redactbytes(b []byte, r []Redaction) {
+	for _, x := range ShiftRedactions(r, 0, len(b), 0) {
+		clear(b[x.Offset : x.Offset+x.Length])
+	}
+}
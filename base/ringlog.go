@@ -0,0 +1,165 @@
+package base
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type RingLogStream interface {
+	Stream
+	// recent traffic as text2pcap hex dump, import into wireshark with direction indication and
+	// timestamp format %Y-%m-%d %H:%M:%S.%f, every read/write is a separate packet
+	Dump() []byte
+}
+
+type ringrecord struct {
+	rx   bool
+	at   time.Time
+	data []byte
+}
+
+// keeps last size bytes of read/written data in memory, dump it when something above fails
+type ringlog struct {
+	inner   Stream
+	size    int
+	mu      sync.Mutex
+	records []ringrecord
+	total   int
+
+	redactions []Redaction // of the next write only
+}
+
+func NewRingLogStream(inner Stream, size int) RingLogStream {
+	return &ringlog{
+		inner: inner,
+		size:  size,
+	}
+}
+
+func (r *ringlog) add(rx bool, p []byte) {
+	if len(p) == 0 || r.size <= 0 {
+		return
+	}
+	if len(p) > r.size {
+		p = p[len(p)-r.size:]
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, ringrecord{rx: rx, at: time.Now(), data: append([]byte(nil), p...)})
+	r.total += len(p)
+	for r.total > r.size { // drop oldest, partially if needed
+		over := r.total - r.size
+		if len(r.records[0].data) <= over {
+			r.total -= len(r.records[0].data)
+			r.records[0] = ringrecord{}
+			r.records = r.records[1:]
+			continue
+		}
+		r.records[0].data = r.records[0].data[over:]
+		r.total -= over
+	}
+}
+
+func (r *ringlog) Dump() []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sb strings.Builder
+	for _, rec := range r.records {
+		if rec.rx {
+			sb.WriteString("I ")
+		} else {
+			sb.WriteString("O ")
+		}
+		sb.WriteString(rec.at.Format("2006-01-02 15:04:05.000000"))
+		sb.WriteByte('\n')
+		for i := 0; i < len(rec.data); i += 16 {
+			fmt.Fprintf(&sb, "%06x", i)
+			for _, b := range rec.data[i:min(i+16, len(rec.data))] {
+				fmt.Fprintf(&sb, " %02x", b)
+			}
+			sb.WriteByte('\n')
+		}
+		sb.WriteByte('\n')
+	}
+	return []byte(sb.String())
+}
+
+func (r *ringlog) Close() error {
+	return r.inner.Close()
+}
+
+func (r *ringlog) Open() error {
+	return r.inner.Open()
+}
+
+func (r *ringlog) OpenContext(ctx context.Context) error {
+	return r.inner.OpenContext(ctx)
+}
+
+func (r *ringlog) Disconnect() error {
+	return r.inner.Disconnect()
+}
+
+func (r *ringlog) SetLogger(logger *zap.SugaredLogger) {
+	r.inner.SetLogger(logger)
+}
+
+func (r *ringlog) SetDeadline(d time.Time) {
+	r.inner.SetDeadline(d)
+}
+
+func (r *ringlog) SetTimeout(d time.Duration) {
+	r.inner.SetTimeout(d)
+}
+
+func (r *ringlog) SetMaxReceivedBytes(m int64) {
+	r.inner.SetMaxReceivedBytes(m)
+}
+
+func (r *ringlog) Read(p []byte) (n int, err error) {
+	n, err = r.inner.Read(p)
+	r.add(true, p[:n])
+	return
+}
+
+func (r *ringlog) Write(src []byte) error {
+	if len(r.redactions) > 0 && redacton.Load() { // dump is a log as well
+		c := append([]byte(nil), src...)
+		redactbytes(c, r.redactions)
+		r.add(false, c)
+	} else {
+		r.add(false, src)
+	}
+	r.redactions = nil
+	return r.inner.Write(src)
+}
+
+func (r *ringlog) GetRxTxBytes() (int64, int64) {
+	return r.inner.GetRxTxBytes()
+}
+
+func (r *ringlog) OverheadBytes() int {
+	return OverheadBytes(r.inner)
+}
+
+func (r *ringlog) Flush() error {
+	return Flush(r.inner)
+}
+
+func (r *ringlog) Drain(d time.Duration) error {
+	return Drain(r.inner, d)
+}
+
+func (r *ringlog) RedactNextWrite(red []Redaction) {
+	r.redactions = red
+	RedactNextWrite(r.inner, red)
+}
+
+func (r *ringlog) ConnectionInfo() map[string]string {
+	return ConnectionInfo(r.inner)
+}
@@ -0,0 +1,169 @@
+package base
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type deadliner interface {
+	SetDeadline(t time.Time) error
+}
+
+type rwcstream struct {
+	rwc             io.ReadWriteCloser
+	dl              deadliner // nil in case rwc cant do deadlines
+	logger          *zap.SugaredLogger
+	isopen          bool
+	closed          bool
+	timeout         time.Duration
+	deadline        time.Time
+	totalincoming   int64
+	totaloutgoing   int64
+	currentincoming int64
+	maxincoming     int64
+
+	redactions []Redaction // of the next write only
+}
+
+// adapts already connected rwc (serial port from other library, net.Conn, fixture) to Stream. Open only marks it usable, Close is no-op
+// as usual (no association here) and Disconnect closes rwc for good. Deadline and timeout work only when rwc has SetDeadline
+// (net.Conn, os.File), otherwise they are no-ops and reads can block forever.
+func FromReadWriteCloser(rwc io.ReadWriteCloser) Stream {
+	ret := &rwcstream{rwc: rwc}
+	if d, ok := rwc.(deadliner); ok {
+		ret.dl = d
+	}
+	return ret
+}
+
+func (r *rwcstream) logf(format string, v ...any) {
+	if r.logger != nil {
+		r.logger.Infof(format, v...)
+	}
+}
+
+func (r *rwcstream) Close() error {
+	return nil
+}
+
+func (r *rwcstream) Open() error {
+	if r.closed {
+		return fmt.Errorf("underlying stream is already closed")
+	}
+	r.isopen = true
+	return nil
+}
+
+func (r *rwcstream) OpenContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d, ok := ctx.Deadline(); ok {
+		r.deadline = d
+	}
+	return r.Open()
+}
+
+func (r *rwcstream) Disconnect() error {
+	if r.isopen {
+		r.isopen = false
+		r.closed = true
+		_ = r.rwc.Close()
+		r.logf("Total bytes incoming: %v, outgoing: %v", r.totalincoming, r.totaloutgoing)
+	}
+	return nil
+}
+
+func (r *rwcstream) SetLogger(logger *zap.SugaredLogger) {
+	r.logger = logger
+}
+
+func (r *rwcstream) SetDeadline(t time.Time) {
+	r.deadline = t
+}
+
+func (r *rwcstream) SetTimeout(t time.Duration) {
+	r.timeout = t
+}
+
+func (r *rwcstream) SetMaxReceivedBytes(m int64) {
+	r.currentincoming = 0
+	r.maxincoming = m
+}
+
+func (r *rwcstream) setcommdeadline() {
+	if r.dl == nil {
+		return
+	}
+	var d time.Time
+	if r.timeout > 0 {
+		d = time.Now().Add(r.timeout)
+	}
+	if !r.deadline.IsZero() && (d.IsZero() || r.deadline.Before(d)) {
+		d = r.deadline
+	}
+	_ = r.dl.SetDeadline(d)
+}
+
+func (r *rwcstream) Read(p []byte) (int, error) {
+	if !r.isopen {
+		return 0, ErrNotOpened
+	}
+	if len(p) == 0 {
+		return 0, ErrNothingToRead
+	}
+	r.setcommdeadline()
+	n, err := r.rwc.Read(p)
+	r.totalincoming += int64(n)
+	r.currentincoming += int64(n)
+	if r.maxincoming > 0 && r.currentincoming > r.maxincoming {
+		return 0, fmt.Errorf("received more than allowed")
+	}
+	if n > 0 && r.logger != nil {
+		r.logger.Debugf(LogHex("RX", p[:n]))
+	}
+	if err != nil && errors.Is(err, os.ErrDeadlineExceeded) {
+		err = ErrCommunicationTimeout
+	}
+	return n, err
+}
+
+func (r *rwcstream) RedactNextWrite(red []Redaction) {
+	r.redactions = red
+}
+
+func (r *rwcstream) Write(src []byte) error {
+	if !r.isopen {
+		return ErrNotOpened
+	}
+	red := r.redactions
+	r.redactions = nil
+	off := 0
+	for len(src) > 0 {
+		r.setcommdeadline()
+		n, err := r.rwc.Write(src)
+		r.totaloutgoing += int64(n)
+		if n > 0 && r.logger != nil {
+			r.logger.Debugf(LogHexRedacted("TX", src[:n], ShiftRedactions(red, off, n, 0)))
+		}
+		off += n
+		if err != nil {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				return ErrCommunicationTimeout
+			}
+			return fmt.Errorf("write failed: %w", err)
+		}
+		src = src[n:]
+	}
+	return nil
+}
+
+func (r *rwcstream) GetRxTxBytes() (int64, int64) {
+	return r.totalincoming, r.totaloutgoing
+}
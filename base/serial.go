@@ -1,5 +1,7 @@
 package base
 
+import "time"
+
 type SerialDataBits int
 type SerialParity int
 type SerialStopBits int
@@ -39,4 +41,9 @@ type SerialStream interface {
 	SetSpeed(baudRate int, dataBits SerialDataBits, parity SerialParity, stopBits SerialStopBits) error
 	SetFlowControl(flowControl SerialFlowControl) error
 	SetDTR(dtr bool) error
+	SetRTS(rts bool) error
+	// raise the line, wait d and lower it again, wake up or reset of some optical probes and modems
+	PulseDTR(d time.Duration) error
+	PulseRTS(d time.Duration) error
+	CurrentSettings() SerialStreamSettings // what is in effect, including values reported by the other side
 }
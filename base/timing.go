@@ -0,0 +1,97 @@
+package base
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// reports duration of each open/read/write of the inner stream, wrap more layers to see where the time is spent
+type timing struct {
+	inner Stream
+	sink  func(op string, d time.Duration)
+}
+
+func NewTimingStream(inner Stream, sink func(op string, d time.Duration)) Stream {
+	return &timing{
+		inner: inner,
+		sink:  sink,
+	}
+}
+
+func (t *timing) Close() error {
+	return t.inner.Close()
+}
+
+func (t *timing) Open() error {
+	start := time.Now()
+	err := t.inner.Open()
+	t.sink("open", time.Since(start))
+	return err
+}
+
+func (t *timing) OpenContext(ctx context.Context) error {
+	start := time.Now()
+	err := t.inner.OpenContext(ctx)
+	t.sink("open", time.Since(start))
+	return err
+}
+
+func (t *timing) Disconnect() error {
+	return t.inner.Disconnect()
+}
+
+func (t *timing) SetLogger(logger *zap.SugaredLogger) {
+	t.inner.SetLogger(logger)
+}
+
+func (t *timing) SetDeadline(d time.Time) {
+	t.inner.SetDeadline(d)
+}
+
+func (t *timing) SetTimeout(d time.Duration) {
+	t.inner.SetTimeout(d)
+}
+
+func (t *timing) SetMaxReceivedBytes(m int64) {
+	t.inner.SetMaxReceivedBytes(m)
+}
+
+func (t *timing) Read(p []byte) (n int, err error) {
+	start := time.Now()
+	n, err = t.inner.Read(p)
+	t.sink("read", time.Since(start))
+	return
+}
+
+func (t *timing) Write(src []byte) error {
+	start := time.Now()
+	err := t.inner.Write(src)
+	t.sink("write", time.Since(start))
+	return err
+}
+
+func (t *timing) GetRxTxBytes() (int64, int64) {
+	return t.inner.GetRxTxBytes()
+}
+
+func (t *timing) OverheadBytes() int {
+	return OverheadBytes(t.inner)
+}
+
+func (t *timing) Flush() error {
+	return Flush(t.inner)
+}
+
+func (t *timing) Drain(d time.Duration) error {
+	return Drain(t.inner, d)
+}
+
+func (t *timing) RedactNextWrite(red []Redaction) {
+	RedactNextWrite(t.inner, red)
+}
+
+func (t *timing) ConnectionInfo() map[string]string {
+	return ConnectionInfo(t.inner)
+}
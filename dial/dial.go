@@ -0,0 +1,260 @@
+package dial
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cybroslabs/libdlms-go/base"
+	"github.com/cybroslabs/libdlms-go/hdlc"
+	"github.com/cybroslabs/libdlms-go/llc"
+	"github.com/cybroslabs/libdlms-go/rfc2217"
+	"github.com/cybroslabs/libdlms-go/tcp"
+	"github.com/cybroslabs/libdlms-go/wrapper"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// builds composed transport from dsn, layers are in scheme from the top one, physical one is the last, e.g.
+//
+//	tcp://host:4059?timeout=20s
+//	wrapper+tcp://host:4059?source=1&destination=1
+//	hdlc+tcp://host:4059?client=16&logical=1&physical=17
+//	llc+hdlc+rfc2217://host:2217?baud=9600&client=16&logical=1&physical=17
+//
+// every layer takes its own query parameters, unknown parameters are an error, returned stream is not opened
+func Dial(dsn string) (base.Stream, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid dsn: %w", err)
+	}
+	layers := strings.Split(strings.ToLower(u.Scheme), "+")
+	q := &query{values: u.Query(), used: make(map[string]bool)}
+
+	timeout, err := q.duration("timeout", defaultTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	// physical layer first
+	host := u.Hostname()
+	if host == "" {
+		return nil, fmt.Errorf("missing host")
+	}
+	if u.Port() == "" {
+		return nil, fmt.Errorf("missing port")
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, fmt.Errorf("invalid port %s", u.Port())
+	}
+
+	var s base.Stream
+	phys := layers[len(layers)-1]
+	switch phys {
+	case "tcp":
+		s = tcp.New(host, port, timeout)
+	case "rfc2217":
+		settings, err := serialsettings(q)
+		if err != nil {
+			return nil, err
+		}
+		s = rfc2217.New(tcp.New(host, port, timeout), settings)
+	default:
+		return nil, fmt.Errorf("unsupported physical layer %s, only tcp or rfc2217", phys)
+	}
+
+	// and then the rest from the bottom
+	below := phys
+	for i := len(layers) - 2; i >= 0; i-- {
+		l := layers[i]
+		switch l {
+		case "hdlc":
+			if below != "tcp" && below != "rfc2217" {
+				return nil, fmt.Errorf("hdlc has to be directly over tcp or rfc2217")
+			}
+			settings, err := hdlcsettings(q)
+			if err != nil {
+				return nil, err
+			}
+			s, err = hdlc.New(s, settings)
+			if err != nil {
+				return nil, err
+			}
+		case "llc":
+			if below != "hdlc" {
+				return nil, fmt.Errorf("llc has to be over hdlc")
+			}
+			s = llc.New(s)
+		case "wrapper":
+			if below != "tcp" && below != "rfc2217" {
+				return nil, fmt.Errorf("wrapper has to be directly over tcp or rfc2217")
+			}
+			src, err := q.uint("source", 1, 0xffff)
+			if err != nil {
+				return nil, err
+			}
+			dst, err := q.uint("destination", 1, 0xffff)
+			if err != nil {
+				return nil, err
+			}
+			s, err = wrapper.New(s, uint16(src), uint16(dst))
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unsupported layer %s", l)
+		}
+		below = l
+	}
+
+	if err = q.unused(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func hdlcsettings(q *query) (*hdlc.Settings, error) {
+	var s hdlc.Settings
+	client, err := q.uint("client", 16, 0x7f)
+	if err != nil {
+		return nil, err
+	}
+	logical, err := q.uint("logical", 1, 0x3fff)
+	if err != nil {
+		return nil, err
+	}
+	physical, err := q.uint("physical", 0, 0x3fff)
+	if err != nil {
+		return nil, err
+	}
+	maxrcv, err := q.uint("maxrcv", 0, 0xffff) // hdlc.New puts it into allowed range
+	if err != nil {
+		return nil, err
+	}
+	maxsnd, err := q.uint("maxsnd", 0, 0xffff)
+	if err != nil {
+		return nil, err
+	}
+	retransmits, err := q.uint("retransmits", 0, 100)
+	if err != nil {
+		return nil, err
+	}
+	snrmretransmits, err := q.uint("snrmretransmits", 0, 100)
+	if err != nil {
+		return nil, err
+	}
+	s.Client = byte(client)
+	s.Logical = uint16(logical)
+	s.Physical = uint16(physical)
+	s.MaxRcv = uint(maxrcv)
+	s.MaxSnd = uint(maxsnd)
+	s.Retransmits = int(retransmits)
+	s.SnrmRetransmits = int(snrmretransmits)
+	return &s, nil
+}
+
+func serialsettings(q *query) (*base.SerialStreamSettings, error) {
+	baud, err := q.uint("baud", 9600, 1000000)
+	if err != nil {
+		return nil, err
+	}
+	databits, err := q.uint("databits", 8, 8)
+	if err != nil {
+		return nil, err
+	}
+	if databits < 5 {
+		return nil, fmt.Errorf("invalid databits %d", databits)
+	}
+	s := base.SerialStreamSettings{
+		BaudRate: int(baud),
+		DataBits: base.SerialDataBits(databits),
+	}
+	switch p := q.str("parity", "none"); p {
+	case "none":
+		s.Parity = base.SerialNoParity
+	case "odd":
+		s.Parity = base.SerialOddParity
+	case "even":
+		s.Parity = base.SerialEvenParity
+	case "mark":
+		s.Parity = base.SerialMarkParity
+	case "space":
+		s.Parity = base.SerialSpaceParity
+	default:
+		return nil, fmt.Errorf("invalid parity %s", p)
+	}
+	switch sb := q.str("stopbits", "1"); sb {
+	case "1":
+		s.StopBits = base.SerialOneStopBit
+	case "2":
+		s.StopBits = base.SerialTwoStopBits
+	case "1.5":
+		s.StopBits = base.SerialOneAndHalfStopBits
+	default:
+		return nil, fmt.Errorf("invalid stopbits %s", sb)
+	}
+	switch f := q.str("flow", "none"); f {
+	case "none":
+		s.FlowControl = base.SerialNoFlowControl
+	case "sw":
+		s.FlowControl = base.SerialSWFlowControl
+	case "hw":
+		s.FlowControl = base.SerialHWFlowControl
+	default:
+		return nil, fmt.Errorf("invalid flow control %s", f)
+	}
+	return &s, nil
+}
+
+// query parameters with tracking of the used ones
+type query struct {
+	values url.Values
+	used   map[string]bool
+}
+
+func (q *query) str(name string, def string) string {
+	q.used[name] = true
+	v := q.values.Get(name)
+	if v == "" {
+		return def
+	}
+	return strings.ToLower(v)
+}
+
+func (q *query) uint(name string, def uint64, max uint64) (uint64, error) {
+	q.used[name] = true
+	v := q.values.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	r, err := strconv.ParseUint(v, 0, 64)
+	if err != nil || r > max {
+		return 0, fmt.Errorf("invalid %s value %s", name, v)
+	}
+	return r, nil
+}
+
+func (q *query) duration(name string, def time.Duration) (time.Duration, error) {
+	q.used[name] = true
+	v := q.values.Get(name)
+	if v == "" {
+		return def, nil
+	}
+	r, err := time.ParseDuration(v)
+	if err != nil || r < 0 {
+		return 0, fmt.Errorf("invalid %s value %s", name, v)
+	}
+	return r, nil
+}
+
+func (q *query) unused() error {
+	for k := range q.values {
+		if !q.used[k] {
+			return fmt.Errorf("unknown or not applicable parameter %s", k)
+		}
+	}
+	return nil
+}
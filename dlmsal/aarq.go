@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/binary"
 	"fmt"
+
+	"github.com/cybroslabs/libdlms-go/base"
 )
 
 type AssociationResult byte
@@ -44,9 +46,25 @@ type initiateResponse struct {
 type confirmedServiceErrorTag byte
 
 const (
-	TagErrInitiateError confirmedServiceErrorTag = 1
-	TagErrRead          confirmedServiceErrorTag = 5
-	TagErrWrite         confirmedServiceErrorTag = 6
+	TagErrInitiateError        confirmedServiceErrorTag = 1
+	TagErrGetStatus            confirmedServiceErrorTag = 2
+	TagErrGetNameList          confirmedServiceErrorTag = 3
+	TagErrGetVariableAttribute confirmedServiceErrorTag = 4
+	TagErrRead                 confirmedServiceErrorTag = 5
+	TagErrWrite                confirmedServiceErrorTag = 6
+	TagErrGetDataSetAttribute  confirmedServiceErrorTag = 7
+	TagErrGetTIAttribute       confirmedServiceErrorTag = 8
+	TagErrChangeScope          confirmedServiceErrorTag = 9
+	TagErrStart                confirmedServiceErrorTag = 10
+	TagErrStop                 confirmedServiceErrorTag = 11
+	TagErrResume               confirmedServiceErrorTag = 12
+	TagErrMakeUsable           confirmedServiceErrorTag = 13
+	TagErrInitiateLoad         confirmedServiceErrorTag = 14
+	TagErrLoadSegment          confirmedServiceErrorTag = 15
+	TagErrTerminateLoad        confirmedServiceErrorTag = 16
+	TagErrInitiateUpLoad       confirmedServiceErrorTag = 17
+	TagErrUpLoadSegment        confirmedServiceErrorTag = 18
+	TagErrTerminateUpLoad      confirmedServiceErrorTag = 19
 )
 
 type serviceErrorTag byte
@@ -64,12 +82,16 @@ const (
 	TagErrOtherError           serviceErrorTag = 10
 )
 
-type confirmedServiceError struct {
+type ConfirmedServiceError struct {
 	ConfirmedServiceError confirmedServiceErrorTag
 	ServiceError          serviceErrorTag
 	Value                 byte
 }
 
+func (e *ConfirmedServiceError) Error() string {
+	return fmt.Sprintf("confirmed service error: %v, service error: %v, value: %v", e.ConfirmedServiceError, e.ServiceError, e.Value)
+}
+
 type ApplicationContext byte
 
 // Application context definitions
@@ -137,9 +159,10 @@ const (
 	ConformanceBlockAction            = 0b000000000000000000000001
 )
 
-type aaretag struct {
-	tag  byte
-	data []byte
+// raw aare element, tag is the whole ber identifier byte (e.g. 0xa1) and data is its content
+type AareTag struct {
+	Tag  byte
+	Data []byte
 }
 
 type AAResponse struct {
@@ -148,13 +171,21 @@ type AAResponse struct {
 	SourceDiagnostic       SourceDiagnostic
 	SystemTitle            []byte
 	initiateResponse       *initiateResponse
-	confirmedServiceError  *confirmedServiceError
+	confirmedServiceError  *ConfirmedServiceError
 }
 
 func putappctxname(dst *bytes.Buffer, settings *DlmsSettings) {
 	// not so exactly correct things, but for speed sake
 	dst.WriteByte(BERTypeContext | BERTypeConstructed | PduTypeApplicationContextName)
-	dst.Write([]byte{0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01})
+	if len(settings.ApplicationContextOID) != 0 { // custom oid prefix, context id is still appended
+		l := len(settings.ApplicationContextOID) + 1
+		dst.WriteByte(byte(l + 2))
+		dst.WriteByte(0x06)
+		dst.WriteByte(byte(l))
+		dst.Write(settings.ApplicationContextOID)
+	} else {
+		dst.Write([]byte{0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01})
+	}
 	dst.WriteByte(byte(settings.applicationContext))
 }
 
@@ -167,11 +198,33 @@ func putmechname(dst *bytes.Buffer, settings *DlmsSettings) {
 	dst.WriteByte(byte(settings.authentication))
 }
 
+// authentication-value choice inside calling-authentication-value
+const (
+	AuthValueCharString byte = 0x80 // [0] graphic string
+	AuthValueBitString  byte = 0x81 // [1] bit string
+)
+
+func authvaluetag(settings *DlmsSettings) byte {
+	if settings.AuthenticationValueTag != 0 {
+		return settings.AuthenticationValueTag
+	}
+	// green book maps lls password and ctos challenge of every hls mechanism (md5, sha1, gmac, sha256, ecdsa) to charstring,
+	// hashes and signatures go only in reply-to-hls-authentication action, so there is nothing to choose by mechanism here
+	return AuthValueCharString
+}
+
 func putsecvalues(dst *bytes.Buffer, settings *DlmsSettings) {
 	if settings.authentication == AuthenticationNone {
 		return
 	}
-	encodetag2(dst, BERTypeContext|BERTypeConstructed|PduTypeCallingAuthenticationValue, 0x80, settings.password)
+	switch t := authvaluetag(settings); t {
+	case AuthValueBitString: // leading unused bits count, value is always whole bytes
+		v := make([]byte, 1+len(settings.password))
+		copy(v[1:], settings.password)
+		encodetag2(dst, BERTypeContext|BERTypeConstructed|PduTypeCallingAuthenticationValue, t, v)
+	default:
+		encodetag2(dst, BERTypeContext|BERTypeConstructed|PduTypeCallingAuthenticationValue, t, settings.password)
+	}
 }
 
 func putsystitle(dst *bytes.Buffer, settings *DlmsSettings) {
@@ -216,7 +269,8 @@ func (d *dlmsal) createxdlms(dst *bytes.Buffer) {
 	encodetag2(dst, BERTypeContext|BERTypeConstructed|PduTypeUserInformation, 0x04, xdlms)
 }
 
-func (d *dlmsal) encodeaarq() (out []byte, err error) {
+// secret is the position of calling-authentication-value itself in out, zero length when there is none
+func (d *dlmsal) encodeaarq() (out []byte, secret base.Redaction, err error) {
 	var buf bytes.Buffer
 	var content bytes.Buffer
 	s := d.settings
@@ -228,73 +282,86 @@ func (d *dlmsal) encodeaarq() (out []byte, err error) {
 	}
 	putmechname(&content, s)
 	putsecvalues(&content, s)
+	secretend := content.Len() // value is always at the end of its tag
 	d.createxdlms(&content)
 
 	encodetag(&buf, byte(TagAARQ), content.Bytes())
 	out = buf.Bytes()
+	if s.authentication != AuthenticationNone {
+		secret.Offset = len(out) - content.Len() + secretend - len(s.password)
+		secret.Length = len(s.password)
+	}
 	return
 }
 
-func decodeaare(src []byte, tmp *tmpbuffer) ([]aaretag, error) {
-	ret := make([]aaretag, 0, 20)
+func decodeaare(src []byte, tmp *tmpbuffer) ([]AareTag, error) {
+	ret := make([]AareTag, 0, 20)
 	for len(src) > 0 {
 		tag, l, data, err := decodetag(src, tmp)
 		if err != nil {
 			return nil, err
 		}
-		ret = append(ret, aaretag{tag: tag, data: data})
+		ret = append(ret, AareTag{Tag: tag, Data: data})
 		src = src[l:]
 	}
 	return ret, nil
 }
 
-func parseApplicationContextName(tag *aaretag) (out ApplicationContext, err error) {
-	if len(tag.data) != 9 {
+func parseApplicationContextName(tag *AareTag, settings *DlmsSettings) (out ApplicationContext, err error) {
+	if len(settings.ApplicationContextOID) != 0 { // only oid structure is checked, meter can answer with its own prefix
+		if len(tag.Data) < 3 || tag.Data[0] != 0x06 || int(tag.Data[1]) != len(tag.Data)-2 {
+			err = fmt.Errorf("invalid A1 tag content")
+			return
+		}
+		out = ApplicationContext(tag.Data[len(tag.Data)-1])
+		return
+	}
+	if len(tag.Data) != 9 {
 		err = fmt.Errorf("invalid A1 tag length")
 		return
 	}
 	rsp := []byte{0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01}
-	if !bytes.Equal(tag.data[:8], rsp) {
+	if !bytes.Equal(tag.Data[:8], rsp) {
 		err = fmt.Errorf("invalid A1 tag content")
 		return
 	}
-	out = ApplicationContext(tag.data[8])
+	out = ApplicationContext(tag.Data[8])
 	return
 }
 
-func parseAssociationResult(tag *aaretag) (out AssociationResult, err error) {
-	if len(tag.data) != 3 {
+func parseAssociationResult(tag *AareTag) (out AssociationResult, err error) {
+	if len(tag.Data) != 3 {
 		err = fmt.Errorf("invalid A2 tag length")
 		return
 	}
 	rsp := []byte{0x02, 0x01}
-	if !bytes.Equal(tag.data[:2], rsp) {
+	if !bytes.Equal(tag.Data[:2], rsp) {
 		err = fmt.Errorf("invalid A2 tag content")
 		return
 	}
-	out = AssociationResult(tag.data[2])
+	out = AssociationResult(tag.Data[2])
 	return
 }
 
-func parseAssociateSourceDiagnostic(tag *aaretag) (out SourceDiagnostic, err error) {
-	if len(tag.data) != 5 {
+func parseAssociateSourceDiagnostic(tag *AareTag) (out SourceDiagnostic, err error) {
+	if len(tag.Data) != 5 {
 		err = fmt.Errorf("invalid A3 tag length")
 		return
 	}
 	rsp := []byte{0x03, 0x02, 0x01}
-	if !bytes.Equal(tag.data[1:4], rsp) {
+	if !bytes.Equal(tag.Data[1:4], rsp) {
 		err = fmt.Errorf("invalid A3 tag content")
 		return
 	}
-	out = SourceDiagnostic(tag.data[4])
+	out = SourceDiagnostic(tag.Data[4])
 	return
 }
 
-func parseAPTitle(tag *aaretag, tmp *tmpbuffer) (out []byte, err error) {
-	if len(tag.data) < 2 {
+func parseAPTitle(tag *AareTag, tmp *tmpbuffer) (out []byte, err error) {
+	if len(tag.Data) < 2 {
 		return nil, fmt.Errorf("invalid A4 tag length")
 	}
-	t, _, d, err := decodetag(tag.data, tmp)
+	t, _, d, err := decodetag(tag.Data, tmp)
 	if err != nil {
 		return nil, err
 	}
@@ -305,11 +372,11 @@ func parseAPTitle(tag *aaretag, tmp *tmpbuffer) (out []byte, err error) {
 	return
 }
 
-func parseSenderAcseRequirements(tag *aaretag, tmp *tmpbuffer) (stoc []byte, err error) {
-	if len(tag.data) < 2 {
+func parseSenderAcseRequirements(tag *AareTag, tmp *tmpbuffer) (stoc []byte, err error) {
+	if len(tag.Data) < 2 {
 		return nil, fmt.Errorf("invalid AA tag length")
 	}
-	t, _, d, err := decodetag(tag.data, tmp)
+	t, _, d, err := decodetag(tag.Data, tmp)
 	if err != nil {
 		return nil, err
 	}
@@ -320,12 +387,12 @@ func parseSenderAcseRequirements(tag *aaretag, tmp *tmpbuffer) (stoc []byte, err
 	return
 }
 
-func (al *dlmsal) parseUserInformation(tag *aaretag) (ir *initiateResponse, cse *confirmedServiceError, err error) {
-	if len(tag.data) < 6 {
+func (al *dlmsal) parseUserInformation(tag *AareTag) (ir *initiateResponse, cse *ConfirmedServiceError, err error) {
+	if len(tag.Data) < 6 {
 		err = fmt.Errorf("invalid BE tag length")
 		return
 	}
-	t, _, d, err := decodetag(tag.data, &al.tmpbuffer)
+	t, _, d, err := decodetag(tag.Data, &al.tmpbuffer)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -335,7 +402,7 @@ func (al *dlmsal) parseUserInformation(tag *aaretag) (ir *initiateResponse, cse
 	return al.parseUserInformationtag(d)
 }
 
-func (al *dlmsal) parseUserInformationtag(d []byte) (ir *initiateResponse, cse *confirmedServiceError, err error) {
+func (al *dlmsal) parseUserInformationtag(d []byte) (ir *initiateResponse, cse *ConfirmedServiceError, err error) {
 	if d[0] == byte(TagInitiateResponse) {
 		iir, err := decodeInitiateResponse(d[1:])
 		return &iir, nil, err
@@ -406,7 +473,7 @@ func decodeInitiateResponse(src []byte) (out initiateResponse, err error) {
 	return
 }
 
-func decodeConfirmedServiceError(src []byte) (out confirmedServiceError, err error) {
+func decodeConfirmedServiceError(src []byte) (out ConfirmedServiceError, err error) {
 	if len(src) < 3 {
 		err = fmt.Errorf("invalid service error length")
 		return
@@ -169,27 +169,34 @@ func recastslice(trg reflect.Value, data *DlmsData) error {
 			return fmt.Errorf("invalid target type: %v", trg.Type())
 		}
 	case []DlmsData:
-		if trg.IsNil() || trg.Cap() < len(v) {
-			trg.Set(reflect.MakeSlice(trg.Type(), len(v), len(v)))
-		} else {
-			trg.SetLen(len(v))
-		}
-		for i := 0; i < len(v); i++ {
-			vv := trg.Index(i)
-			if vv.Kind() == reflect.Pointer && vv.IsNil() {
-				vv.Set(reflect.New(vv.Type().Elem()))
-			}
-			err := recast(reflect.Indirect(vv), &v[i])
-			if err != nil {
-				return err
-			}
-		}
+		return recastdataslice(trg, v)
+	case DlmsCompactArray: // items are already decoded to the same shape as normal array
+		return recastdataslice(trg, v.value)
 	default:
 		return fmt.Errorf("unexpected type %T", v)
 	}
 	return nil
 }
 
+func recastdataslice(trg reflect.Value, v []DlmsData) error {
+	if trg.IsNil() || trg.Cap() < len(v) {
+		trg.Set(reflect.MakeSlice(trg.Type(), len(v), len(v)))
+	} else {
+		trg.SetLen(len(v))
+	}
+	for i := 0; i < len(v); i++ {
+		vv := trg.Index(i)
+		if vv.Kind() == reflect.Pointer && vv.IsNil() {
+			vv.Set(reflect.New(vv.Type().Elem()))
+		}
+		err := recast(reflect.Indirect(vv), &v[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func recaststring(trg reflect.Value, data *DlmsData) error {
 	switch v := data.Value.(type) {
 	case string:
@@ -209,6 +216,7 @@ func recaststring(trg reflect.Value, data *DlmsData) error {
 func recastvalue(trg reflect.Value, data *DlmsData) error {
 	value := Value{Type: Unknown}
 	switch v := data.Value.(type) { // Tag should be also considered
+	case nil: // null data, keep unknown type
 	case bool:
 		value.Type = Boolean
 		value.Value = v
@@ -227,6 +235,9 @@ func recastvalue(trg reflect.Value, data *DlmsData) error {
 	case uint8:
 		value.Type = UnsignedInt
 		value.Value = uint64(v)
+	case DlmsEnum:
+		value.Type = UnsignedInt
+		value.Value = uint64(v)
 	case uint16:
 		value.Type = UnsignedInt
 		value.Value = uint64(v)
@@ -245,6 +256,9 @@ func recastvalue(trg reflect.Value, data *DlmsData) error {
 	case string:
 		value.Type = String
 		value.Value = v
+	case DlmsDateTime:
+		value.Type = DateTime
+		value.Value = v
 	case []byte:
 		if len(v) == 12 {
 			d, err := NewDlmsDateTimeFromSlice(v)
@@ -299,6 +313,8 @@ func recastbool(trg reflect.Value, data *DlmsData) error {
 		trg.SetBool(v != 0)
 	case uint8:
 		trg.SetBool(v != 0)
+	case DlmsEnum:
+		trg.SetBool(v != 0)
 	case uint16:
 		trg.SetBool(v != 0)
 	case uint32:
@@ -321,6 +337,8 @@ func recastuint(trg reflect.Value, data *DlmsData) error {
 		}
 	case uint8:
 		trg.SetUint(uint64(v))
+	case DlmsEnum:
+		trg.SetUint(uint64(v))
 	case uint16:
 		trg.SetUint(uint64(v))
 	case uint32:
@@ -353,6 +371,16 @@ func recastfloat(trg reflect.Value, data *DlmsData) error {
 		trg.SetFloat(float64(v))
 	case int64:
 		trg.SetFloat(float64(v))
+	case uint8:
+		trg.SetFloat(float64(v))
+	case DlmsEnum:
+		trg.SetFloat(float64(v))
+	case uint16:
+		trg.SetFloat(float64(v))
+	case uint32:
+		trg.SetFloat(float64(v))
+	case uint64:
+		trg.SetFloat(float64(v))
 	default:
 		return fmt.Errorf("unexpected type %T", v)
 	}
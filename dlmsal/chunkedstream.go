@@ -35,8 +35,8 @@ func (d *chunkedstream) Rewind() {
 	d.curr = d.first
 }
 
-func (d *chunkedstream) CopyFrom(src io.Reader) (err error) {
-	curr := d.curr
+func (d *chunkedstream) CopyFrom(src io.Reader) (err error) { // always appends, so it can continue after failed copy
+	curr := d.last
 	for {
 		if curr.size == memchunksize { // a new chunk
 			d.last.next = &chunkitem{}
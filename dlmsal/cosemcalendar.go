@@ -0,0 +1,313 @@
+package dlmsal
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+type SeasonProfile struct {
+	Name     []byte
+	Start    DlmsDateTime // usually with wildcard year
+	WeekName []byte
+}
+
+type WeekProfile struct {
+	Name []byte
+	Days [7]uint8 // day ids from monday to sunday
+}
+
+type DayAction struct {
+	Start    DlmsTime
+	Script   DlmsObis // script table to execute, usually tariffication script table
+	Selector uint16
+}
+
+type DayProfile struct {
+	DayId   uint8
+	Actions []DayAction
+}
+
+type ActivityCalendar struct {
+	Name    []byte
+	Seasons []SeasonProfile
+	Weeks   []WeekProfile
+	Days    []DayProfile
+}
+
+type SpecialDay struct {
+	Index uint16
+	Date  DlmsDate // usually with wildcard year for yearly repeated days
+	DayId uint8
+}
+
+// activity calendar (class 20) is spread over attributes, so d is structure of calendar_name, season_profile, week_profile_table
+// and day_profile_table values in this order, so attributes 2-5 for the active or 6-9 for the passive calendar
+func ParseActivityCalendar(d DlmsData) (ActivityCalendar, error) {
+	var ret ActivityCalendar
+	str, err := calstructure(d, 4)
+	if err != nil {
+		return ret, fmt.Errorf("invalid activity calendar: %w", err)
+	}
+	if ret.Name, err = calbytes(str[0]); err != nil {
+		return ret, fmt.Errorf("invalid calendar name: %w", err)
+	}
+
+	seasons, err := calarray(str[1])
+	if err != nil {
+		return ret, fmt.Errorf("invalid season profile: %w", err)
+	}
+	ret.Seasons = make([]SeasonProfile, len(seasons))
+	for i, s := range seasons {
+		if ret.Seasons[i], err = parseseason(s); err != nil {
+			return ret, fmt.Errorf("invalid season profile %d: %w", i, err)
+		}
+	}
+
+	weeks, err := calarray(str[2])
+	if err != nil {
+		return ret, fmt.Errorf("invalid week profile table: %w", err)
+	}
+	ret.Weeks = make([]WeekProfile, len(weeks))
+	for i, w := range weeks {
+		if ret.Weeks[i], err = parseweek(w); err != nil {
+			return ret, fmt.Errorf("invalid week profile %d: %w", i, err)
+		}
+	}
+
+	days, err := calarray(str[3])
+	if err != nil {
+		return ret, fmt.Errorf("invalid day profile table: %w", err)
+	}
+	ret.Days = make([]DayProfile, len(days))
+	for i, dp := range days {
+		if ret.Days[i], err = parseday(dp); err != nil {
+			return ret, fmt.Errorf("invalid day profile %d: %w", i, err)
+		}
+	}
+	return ret, nil
+}
+
+// entries attribute (2) of special days table (class 11)
+func ParseSpecialDays(d DlmsData) ([]SpecialDay, error) {
+	entries, err := calarray(d)
+	if err != nil {
+		return nil, fmt.Errorf("invalid special days table: %w", err)
+	}
+	ret := make([]SpecialDay, len(entries))
+	for i, e := range entries {
+		str, err := calstructure(e, 3)
+		if err != nil {
+			return nil, fmt.Errorf("invalid special day %d: %w", i, err)
+		}
+		s := &ret[i]
+		if err = Cast(&s.Index, str[0]); err != nil {
+			return nil, fmt.Errorf("invalid index of special day %d: %w", i, err)
+		}
+		if s.Date, err = caldate(str[1]); err != nil {
+			return nil, fmt.Errorf("invalid date of special day %d: %w", i, err)
+		}
+		if err = Cast(&s.DayId, str[2]); err != nil {
+			return nil, fmt.Errorf("invalid day id of special day %d: %w", i, err)
+		}
+	}
+	return ret, nil
+}
+
+// inverse of ParseActivityCalendar, elements of the returned structure are values for attributes 6-9 (passive calendar),
+// dates and times are encoded as octet strings as blue book requires
+func NewActivityCalendarData(c ActivityCalendar) DlmsData {
+	seasons := make([]DlmsData, len(c.Seasons))
+	for i, s := range c.Seasons {
+		var st [12]byte
+		putcaldate(st[:], s.Start.Date)
+		putcaltime(st[5:], s.Start.Time)
+		binary.BigEndian.PutUint16(st[9:], uint16(s.Start.Deviation))
+		st[11] = s.Start.Status
+		seasons[i] = DlmsData{Tag: TagStructure, Value: []DlmsData{
+			{Tag: TagOctetString, Value: s.Name},
+			{Tag: TagOctetString, Value: st[:]},
+			{Tag: TagOctetString, Value: s.WeekName},
+		}}
+	}
+
+	weeks := make([]DlmsData, len(c.Weeks))
+	for i, w := range c.Weeks {
+		str := make([]DlmsData, 8)
+		str[0] = DlmsData{Tag: TagOctetString, Value: w.Name}
+		for j, id := range w.Days {
+			str[j+1] = DlmsData{Tag: TagUnsigned, Value: id}
+		}
+		weeks[i] = DlmsData{Tag: TagStructure, Value: str}
+	}
+
+	days := make([]DlmsData, len(c.Days))
+	for i, dp := range c.Days {
+		actions := make([]DlmsData, len(dp.Actions))
+		for j, a := range dp.Actions {
+			var st [4]byte
+			putcaltime(st[:], a.Start)
+			actions[j] = DlmsData{Tag: TagStructure, Value: []DlmsData{
+				{Tag: TagOctetString, Value: st[:]},
+				{Tag: TagOctetString, Value: a.Script},
+				{Tag: TagLongUnsigned, Value: a.Selector},
+			}}
+		}
+		days[i] = DlmsData{Tag: TagStructure, Value: []DlmsData{
+			{Tag: TagUnsigned, Value: dp.DayId},
+			{Tag: TagArray, Value: actions},
+		}}
+	}
+
+	return DlmsData{Tag: TagStructure, Value: []DlmsData{
+		{Tag: TagOctetString, Value: c.Name},
+		{Tag: TagArray, Value: seasons},
+		{Tag: TagArray, Value: weeks},
+		{Tag: TagArray, Value: days},
+	}}
+}
+
+// value for entries attribute of special days table, for writing whole table or as a parameter of insert method
+func NewSpecialDaysData(days []SpecialDay) DlmsData {
+	ret := make([]DlmsData, len(days))
+	for i, s := range days {
+		var dt [5]byte
+		putcaldate(dt[:], s.Date)
+		ret[i] = DlmsData{Tag: TagStructure, Value: []DlmsData{
+			{Tag: TagLongUnsigned, Value: s.Index},
+			{Tag: TagOctetString, Value: dt[:]},
+			{Tag: TagUnsigned, Value: s.DayId},
+		}}
+	}
+	return DlmsData{Tag: TagArray, Value: ret}
+}
+
+func parseseason(d DlmsData) (s SeasonProfile, err error) {
+	str, err := calstructure(d, 3)
+	if err != nil {
+		return
+	}
+	if s.Name, err = calbytes(str[0]); err != nil {
+		return
+	}
+	if err = Cast(&s.Start, str[1]); err != nil {
+		return s, fmt.Errorf("invalid season start: %w", err)
+	}
+	s.WeekName, err = calbytes(str[2])
+	return
+}
+
+func parseweek(d DlmsData) (w WeekProfile, err error) {
+	str, err := calstructure(d, 8)
+	if err != nil {
+		return
+	}
+	if w.Name, err = calbytes(str[0]); err != nil {
+		return
+	}
+	for i := range w.Days {
+		if err = Cast(&w.Days[i], str[i+1]); err != nil {
+			return w, fmt.Errorf("invalid day id of weekday %d: %w", i+1, err)
+		}
+	}
+	return
+}
+
+func parseday(d DlmsData) (dp DayProfile, err error) {
+	str, err := calstructure(d, 2)
+	if err != nil {
+		return
+	}
+	if err = Cast(&dp.DayId, str[0]); err != nil {
+		return dp, fmt.Errorf("invalid day id: %w", err)
+	}
+	actions, err := calarray(str[1])
+	if err != nil {
+		return dp, fmt.Errorf("invalid day schedule: %w", err)
+	}
+	dp.Actions = make([]DayAction, len(actions))
+	for i, a := range actions {
+		as, err := calstructure(a, 3)
+		if err != nil {
+			return dp, fmt.Errorf("invalid action %d: %w", i, err)
+		}
+		act := &dp.Actions[i]
+		if act.Start, err = caltime(as[0]); err != nil {
+			return dp, fmt.Errorf("invalid start time of action %d: %w", i, err)
+		}
+		if err = Cast(&act.Script, as[1]); err != nil {
+			return dp, fmt.Errorf("invalid script of action %d: %w", i, err)
+		}
+		if err = Cast(&act.Selector, as[2]); err != nil {
+			return dp, fmt.Errorf("invalid script selector of action %d: %w", i, err)
+		}
+	}
+	return
+}
+
+func calstructure(d DlmsData, n int) ([]DlmsData, error) {
+	str, ok := d.Value.([]DlmsData)
+	if d.Tag != TagStructure || !ok {
+		return nil, fmt.Errorf("expected structure, got tag %d", d.Tag)
+	}
+	if len(str) < n {
+		return nil, fmt.Errorf("expected structure of %d elements, got %d", n, len(str))
+	}
+	return str, nil
+}
+
+func calarray(d DlmsData) ([]DlmsData, error) {
+	arr, ok := d.Value.([]DlmsData)
+	if d.Tag != TagArray || !ok {
+		return nil, fmt.Errorf("expected array, got tag %d", d.Tag)
+	}
+	return arr, nil
+}
+
+func calbytes(d DlmsData) ([]byte, error) {
+	b, ok := d.Value.([]byte)
+	if d.Tag != TagOctetString || !ok {
+		return nil, fmt.Errorf("expected octet string, got tag %d", d.Tag)
+	}
+	return b, nil
+}
+
+// blue book says octet string, but some meters send date/time type directly
+func caltime(d DlmsData) (DlmsTime, error) {
+	switch v := d.Value.(type) {
+	case DlmsTime:
+		return v, nil
+	case []byte:
+		if len(v) != 4 {
+			return DlmsTime{}, fmt.Errorf("invalid time length %d", len(v))
+		}
+		return DlmsTime{Hour: v[0], Minute: v[1], Second: v[2], Hundredths: v[3]}, nil
+	}
+	return DlmsTime{}, fmt.Errorf("unexpected time type %T", d.Value)
+}
+
+func caldate(d DlmsData) (DlmsDate, error) {
+	switch v := d.Value.(type) {
+	case DlmsDate:
+		return v, nil
+	case []byte:
+		if len(v) != 5 {
+			return DlmsDate{}, fmt.Errorf("invalid date length %d", len(v))
+		}
+		return DlmsDate{Year: binary.BigEndian.Uint16(v), Month: v[2], Day: v[3], DayOfWeek: v[4]}, nil
+	}
+	return DlmsDate{}, fmt.Errorf("unexpected date type %T", d.Value)
+}
+
+func putcaldate(b []byte, d DlmsDate) {
+	binary.BigEndian.PutUint16(b, d.Year)
+	b[2] = d.Month
+	b[3] = d.Day
+	b[4] = d.DayOfWeek
+}
+
+func putcaltime(b []byte, t DlmsTime) {
+	b[0] = t.Hour
+	b[1] = t.Minute
+	b[2] = t.Second
+	b[3] = t.Hundredths
+}
@@ -0,0 +1,118 @@
+package dlmsal
+
+import "sync"
+
+// access here is just what the blue book expects usually, real rights are defined per association in the meter
+type AttributeDescriptor struct {
+	Index    int8
+	Name     string
+	Readable bool
+	Writable bool
+}
+
+type MethodDescriptor struct {
+	Index int8
+	Name  string
+}
+
+type ClassDescriptor struct {
+	ClassId    uint16
+	Version    uint8 // highest known version, attributes and methods are for this one
+	Name       string
+	Attributes []AttributeDescriptor
+	Methods    []MethodDescriptor
+}
+
+func (c *ClassDescriptor) Attribute(index int8) (AttributeDescriptor, bool) {
+	for _, a := range c.Attributes {
+		if a.Index == index {
+			return a, true
+		}
+	}
+	return AttributeDescriptor{}, false
+}
+
+func (c *ClassDescriptor) Method(index int8) (MethodDescriptor, bool) {
+	for _, m := range c.Methods {
+		if m.Index == index {
+			return m, true
+		}
+	}
+	return MethodDescriptor{}, false
+}
+
+func ro(index int8, name string) AttributeDescriptor {
+	return AttributeDescriptor{Index: index, Name: name, Readable: true}
+}
+
+func rw(index int8, name string) AttributeDescriptor {
+	return AttributeDescriptor{Index: index, Name: name, Readable: true, Writable: true}
+}
+
+var classesmutex sync.RWMutex
+
+var classes = map[uint16]ClassDescriptor{
+	1: {ClassId: 1, Version: 0, Name: "data",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), rw(2, "value")},
+	},
+	3: {ClassId: 3, Version: 0, Name: "register",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), rw(2, "value"), ro(3, "scaler_unit")},
+		Methods:    []MethodDescriptor{{1, "reset"}},
+	},
+	4: {ClassId: 4, Version: 0, Name: "extended register",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), rw(2, "value"), ro(3, "scaler_unit"), ro(4, "status"), ro(5, "capture_time")},
+		Methods:    []MethodDescriptor{{1, "reset"}},
+	},
+	5: {ClassId: 5, Version: 0, Name: "demand register",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), ro(2, "current_average_value"), ro(3, "last_average_value"), ro(4, "scaler_unit"),
+			ro(5, "status"), ro(6, "capture_time"), ro(7, "start_time_current"), rw(8, "period"), rw(9, "number_of_periods")},
+		Methods: []MethodDescriptor{{1, "reset"}, {2, "next_period"}},
+	},
+	7: {ClassId: 7, Version: 1, Name: "profile generic",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), ro(2, "buffer"), rw(3, "capture_objects"), rw(4, "capture_period"),
+			rw(5, "sort_method"), rw(6, "sort_object"), ro(7, "entries_in_use"), rw(8, "profile_entries")},
+		Methods: []MethodDescriptor{{1, "reset"}, {2, "capture"}},
+	},
+	8: {ClassId: 8, Version: 0, Name: "clock",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), rw(2, "time"), rw(3, "time_zone"), ro(4, "status"), rw(5, "daylight_savings_begin"),
+			rw(6, "daylight_savings_end"), rw(7, "daylight_savings_deviation"), rw(8, "daylight_savings_enabled"), ro(9, "clock_base")},
+		Methods: []MethodDescriptor{{1, "adjust_to_quarter"}, {2, "adjust_to_measuring_period"}, {3, "adjust_to_minute"},
+			{4, "adjust_to_preset_time"}, {5, "preset_adjusting_time"}, {6, "shift_time"}},
+	},
+	9: {ClassId: 9, Version: 0, Name: "script table",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), rw(2, "scripts")},
+		Methods:    []MethodDescriptor{{1, "execute"}},
+	},
+	15: {ClassId: 15, Version: 2, Name: "association ln",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), ro(2, "object_list"), ro(3, "associated_partners_id"), ro(4, "application_context_name"),
+			ro(5, "xdlms_context_info"), ro(6, "authentication_mechanism_name"), rw(7, "secret"), ro(8, "association_status"),
+			ro(9, "security_setup_reference"), rw(10, "user_list"), ro(11, "current_user")},
+		Methods: []MethodDescriptor{{1, "reply_to_hls_authentication"}, {2, "change_hls_secret"}, {3, "add_object"}, {4, "remove_object"},
+			{5, "add_user"}, {6, "remove_user"}},
+	},
+	64: {ClassId: 64, Version: 1, Name: "security setup",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), rw(2, "security_policy"), ro(3, "security_suite"), rw(4, "client_system_title"),
+			ro(5, "server_system_title"), ro(6, "certificates")},
+		Methods: []MethodDescriptor{{1, "security_activate"}, {2, "key_transfer"}, {3, "key_agreement"}, {4, "generate_key_pair"},
+			{5, "generate_certificate_request"}, {6, "import_certificate"}, {7, "export_certificate"}, {8, "remove_certificate"}},
+	},
+	70: {ClassId: 70, Version: 0, Name: "disconnect control",
+		Attributes: []AttributeDescriptor{ro(1, "logical_name"), ro(2, "output_state"), ro(3, "control_state"), rw(4, "control_mode")},
+		Methods:    []MethodDescriptor{{1, "remote_disconnect"}, {2, "remote_reconnect"}},
+	},
+}
+
+// descriptor of known interface class, slices are shared so dont modify them
+func ClassInfo(classId uint16) (ClassDescriptor, bool) {
+	classesmutex.RLock()
+	defer classesmutex.RUnlock()
+	c, ok := classes[classId]
+	return c, ok
+}
+
+// adds or replaces class descriptor, e.g. for manufacturer specific classes or newer versions
+func RegisterClass(desc ClassDescriptor) {
+	classesmutex.Lock()
+	defer classesmutex.Unlock()
+	classes[desc.ClassId] = desc
+}
@@ -0,0 +1,74 @@
+package dlmsal
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	clockClassId           = 8
+	clockAttrTime          = 2
+	clockAttrTimeZone      = 3
+	clockAttrDstDeviation  = 7
+	clockStatusDstActive   = 0x80
+	clockStatusUnspecified = 0xff
+)
+
+var clockObis = DlmsObis{A: 0, B: 0, C: 1, D: 0, E: 0, F: 255}
+
+// meter clock minus ref, positive means meter is ahead. Deviation in the time itself is used when present,
+// otherwise time_zone plus daylight_savings_deviation in case dst bit of the status is set
+func (d *dlmsal) ClockDrift(ref time.Time) (time.Duration, error) {
+	data, err := d.Get([]DlmsLNRequestItem{
+		{ClassId: clockClassId, Obis: clockObis, Attribute: clockAttrTime},
+		{ClassId: clockClassId, Obis: clockObis, Attribute: clockAttrTimeZone},
+		{ClassId: clockClassId, Obis: clockObis, Attribute: clockAttrDstDeviation},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if e, ok := data[0].ResultError(); ok {
+		return 0, e
+	}
+
+	var dt DlmsDateTime
+	switch v := data[0].Value.(type) {
+	case DlmsDateTime:
+		dt = v
+	case []byte:
+		dt, err = NewDlmsDateTimeFromSlice(v)
+		if err != nil {
+			return 0, err
+		}
+	default:
+		return 0, fmt.Errorf("unexpected clock time type %T", data[0].Value)
+	}
+
+	if dt.Deviation == -32768 { // not specified, so count it from the other attributes
+		var tz int16
+		if _, ok := data[1].ResultError(); ok {
+			return 0, fmt.Errorf("clock deviation not specified and time_zone not readable")
+		}
+		if err = Cast(&tz, data[1]); err != nil {
+			return 0, fmt.Errorf("unable to cast time_zone: %w", err)
+		}
+		dev := tz
+		if dt.Status != clockStatusUnspecified && dt.Status&clockStatusDstActive != 0 {
+			var dst int8
+			if _, ok := data[2].ResultError(); ok {
+				return 0, fmt.Errorf("dst is active but daylight_savings_deviation not readable")
+			}
+			if err = Cast(&dst, data[2]); err != nil {
+				return 0, fmt.Errorf("unable to cast daylight_savings_deviation: %w", err)
+			}
+			dev += int16(dst)
+		}
+		dt.Deviation = dev
+	}
+
+	t, err := dt.ToTime()
+	if err != nil {
+		return 0, err
+	}
+	return t.Sub(ref), nil
+}
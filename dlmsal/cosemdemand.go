@@ -0,0 +1,102 @@
+package dlmsal
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	demandRegisterClassId          = 5
+	demandRegisterAttrCurrent      = 2
+	demandRegisterAttrLast         = 3
+	demandRegisterAttrScalerUnit   = 4
+	demandRegisterAttrStatus       = 5
+	demandRegisterAttrCaptureTime  = 6
+	demandRegisterAttrStartCurrent = 7
+	demandRegisterAttrPeriod       = 8
+	demandRegisterAttrPeriods      = 9
+	demandRegisterMethodReset      = 1
+	demandRegisterMethodNextPeriod = 2
+)
+
+// demand register (class 5), averages are already multiplied by 10^scaler
+type DemandRegister struct {
+	CurrentAverage   float64
+	LastAverage      float64
+	Scaler           int8
+	Unit             byte     // use GetUnit for the text
+	Status           DlmsData // type is manufacturer specific, so as received
+	CaptureTime      DlmsDateTime
+	StartTimeCurrent DlmsDateTime
+	Period           uint32 // seconds
+	NumberOfPeriods  uint16
+}
+
+// reads attributes 2-9 of demand register in one request
+func (d *dlmsal) ReadDemandRegister(obis DlmsObis) (ret DemandRegister, err error) {
+	items := make([]DlmsLNRequestItem, demandRegisterAttrPeriods-demandRegisterAttrCurrent+1)
+	for i := range items {
+		items[i] = DlmsLNRequestItem{ClassId: demandRegisterClassId, Obis: obis, Attribute: int8(demandRegisterAttrCurrent + i)}
+	}
+	data, err := d.Get(items)
+	if err != nil {
+		return
+	}
+	for i := range data {
+		if e, ok := data[i].ResultError(); ok {
+			return ret, fmt.Errorf("unable to get demand register attribute %d: %w", demandRegisterAttrCurrent+i, e)
+		}
+	}
+
+	var su scalerunit
+	if err = Cast(&su, data[demandRegisterAttrScalerUnit-demandRegisterAttrCurrent]); err != nil {
+		return ret, fmt.Errorf("invalid scaler_unit: %w", err)
+	}
+	ret.Scaler = su.Scaler
+	ret.Unit = su.Unit
+	ret.Status = data[demandRegisterAttrStatus-demandRegisterAttrCurrent]
+	targets := []struct {
+		attr int
+		v    any
+	}{
+		{demandRegisterAttrCurrent, &ret.CurrentAverage},
+		{demandRegisterAttrLast, &ret.LastAverage},
+		{demandRegisterAttrCaptureTime, &ret.CaptureTime},
+		{demandRegisterAttrStartCurrent, &ret.StartTimeCurrent},
+		{demandRegisterAttrPeriod, &ret.Period},
+		{demandRegisterAttrPeriods, &ret.NumberOfPeriods},
+	}
+	for _, t := range targets {
+		if err = Cast(t.v, data[t.attr-demandRegisterAttrCurrent]); err != nil {
+			return ret, fmt.Errorf("unable to cast demand register attribute %d: %w", t.attr, err)
+		}
+	}
+	m := math.Pow10(int(su.Scaler))
+	ret.CurrentAverage *= m
+	ret.LastAverage *= m
+	return ret, nil
+}
+
+// invokes reset (method 1), averages are cleared and capture time is set
+func (d *dlmsal) ResetDemandRegister(obis DlmsObis) error {
+	return d.demandaction(obis, demandRegisterMethodReset)
+}
+
+// invokes next_period (method 2), current period is closed and the new one starts
+func (d *dlmsal) NextDemandPeriod(obis DlmsObis) error {
+	return d.demandaction(obis, demandRegisterMethodNextPeriod)
+}
+
+func (d *dlmsal) demandaction(obis DlmsObis, method int8) error {
+	param := DlmsData{Tag: TagInteger, Value: int8(0)}
+	ret, err := d.Action(DlmsLNRequestItem{ClassId: demandRegisterClassId, Obis: obis, Attribute: method, SetData: &param})
+	if err != nil {
+		return err
+	}
+	if ret != nil {
+		if e, ok := ret.ResultError(); ok {
+			return e
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package dlmsal
+
+import (
+	"fmt"
+)
+
+// disconnect control (class 70) control_state, attribute 3
+type ControlState byte
+
+const (
+	ControlStateDisconnected         ControlState = 0
+	ControlStateConnected            ControlState = 1
+	ControlStateReadyForReconnection ControlState = 2
+)
+
+func (s ControlState) String() string {
+	switch s {
+	case ControlStateDisconnected:
+		return "disconnected"
+	case ControlStateConnected:
+		return "connected"
+	case ControlStateReadyForReconnection:
+		return "ready for reconnection"
+	}
+	return fmt.Sprintf("unknown control state %d", byte(s))
+}
+
+const (
+	disconnectControlClassId          = 70
+	disconnectControlAttrControlState = 3
+	disconnectControlMethodDisconnect = 1 // remote_disconnect
+	disconnectControlMethodReconnect  = 2 // remote_reconnect
+)
+
+// invokes remote_disconnect (method 1) of the disconnect control object
+func (d *dlmsal) RemoteDisconnect(obis DlmsObis) error {
+	return d.disconnectcontrol(obis, disconnectControlMethodDisconnect)
+}
+
+// invokes remote_reconnect (method 2), depending on control_mode the relay can just get to ready_for_reconnection state
+func (d *dlmsal) RemoteReconnect(obis DlmsObis) error {
+	return d.disconnectcontrol(obis, disconnectControlMethodReconnect)
+}
+
+func (d *dlmsal) ReadDisconnectState(obis DlmsObis) (ControlState, error) {
+	data, err := d.Get([]DlmsLNRequestItem{{ClassId: disconnectControlClassId, Obis: obis, Attribute: disconnectControlAttrControlState}})
+	if err != nil {
+		return 0, err
+	}
+	if e, ok := data[0].ResultError(); ok {
+		return 0, e
+	}
+	if data[0].Tag != TagEnum {
+		return 0, fmt.Errorf("unexpected control state tag %d", data[0].Tag)
+	}
+	v, ok := data[0].Value.(DlmsEnum)
+	if !ok {
+		return 0, fmt.Errorf("unexpected control state type %T", data[0].Value)
+	}
+	return ControlState(v), nil
+}
+
+func (d *dlmsal) disconnectcontrol(obis DlmsObis, method int8) error {
+	param := DlmsData{Tag: TagInteger, Value: int8(0)} // data ::= integer(0) for both methods
+	ret, err := d.Action(DlmsLNRequestItem{ClassId: disconnectControlClassId, Obis: obis, Attribute: method, SetData: &param})
+	if err != nil {
+		return err
+	}
+	if ret != nil {
+		if e, ok := ret.ResultError(); ok {
+			return e
+		}
+	}
+	return nil
+}
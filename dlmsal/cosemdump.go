@@ -0,0 +1,137 @@
+package dlmsal
+
+import (
+	"fmt"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+const (
+	associationLNClassId      = 15
+	associationAttrObjectList = 2
+)
+
+var currentAssociationObis = DlmsObis{A: 0, B: 0, C: 40, D: 0, E: 0, F: 255}
+
+type ObjectListEntry struct {
+	ClassId uint16
+	Version uint8
+	Obis    DlmsObis
+	Access  DlmsData // access_rights as received, different for ln association versions
+}
+
+type DumpedObject struct {
+	Entry  ObjectListEntry
+	Value  DlmsData     // attribute 2 as received
+	Scaled *ScaledValue // only for registers
+	Err    error
+}
+
+// object_list of the current association (0.0.40.0.0.255)
+func (d *dlmsal) ReadObjectList() ([]ObjectListEntry, error) {
+	data, err := d.Get([]DlmsLNRequestItem{{ClassId: associationLNClassId, Obis: currentAssociationObis, Attribute: associationAttrObjectList}})
+	if err != nil {
+		return nil, err
+	}
+	if e, ok := data[0].ResultError(); ok {
+		return nil, e
+	}
+	items, ok := data[0].Value.([]DlmsData)
+	if data[0].Tag != TagArray || !ok {
+		return nil, fmt.Errorf("unexpected object list tag %d", data[0].Tag)
+	}
+
+	ret := make([]ObjectListEntry, len(items))
+	for i, it := range items {
+		str, ok := it.Value.([]DlmsData)
+		if it.Tag != TagStructure || !ok || len(str) < 3 {
+			return nil, fmt.Errorf("invalid object list entry %d", i)
+		}
+		e := &ret[i]
+		if err = Cast(&e.ClassId, str[0]); err != nil {
+			return nil, fmt.Errorf("invalid class of object list entry %d: %w", i, err)
+		}
+		if err = Cast(&e.Version, str[1]); err != nil {
+			return nil, fmt.Errorf("invalid version of object list entry %d: %w", i, err)
+		}
+		ln, ok := str[2].Value.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("invalid logical name of object list entry %d", i)
+		}
+		if e.Obis, err = NewDlmsObisFromSlice(ln); err != nil {
+			return nil, fmt.Errorf("invalid logical name of object list entry %d: %w", i, err)
+		}
+		if len(str) > 3 {
+			e.Access = str[3]
+		}
+	}
+	return ret, nil
+}
+
+// reads attribute 2 of objects from the object list, nil filter means data and register objects,
+// registers are scaled, errors of single objects (also failed batch) are kept in DumpedObject.Err
+func (d *dlmsal) DumpObjects(filter func(ObjectListEntry) bool) ([]DumpedObject, error) {
+	if !d.isopen {
+		return nil, base.ErrNotOpened
+	}
+	if filter == nil {
+		filter = func(e ObjectListEntry) bool { return e.ClassId == 1 || e.ClassId == 3 }
+	}
+	list, err := d.ReadObjectList()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]DumpedObject, 0, len(list))
+	var registers, others []int
+	for _, e := range list {
+		if !filter(e) {
+			continue
+		}
+		if e.ClassId == 3 {
+			registers = append(registers, len(ret))
+		} else {
+			others = append(others, len(ret))
+		}
+		ret = append(ret, DumpedObject{Entry: e})
+	}
+
+	for start := 0; start < len(registers); start += registerBatchSize {
+		batch := registers[start:min(start+registerBatchSize, len(registers))]
+		obis := make([]DlmsObis, len(batch))
+		for i, r := range batch {
+			obis[i] = ret[r].Entry.Obis
+		}
+		values, err := d.ReadRegisters(obis)
+		for i, r := range batch {
+			if err != nil {
+				ret[r].Err = err
+				continue
+			}
+			v := values[i]
+			ret[r].Value = v.Raw
+			ret[r].Scaled = &v
+			ret[r].Err = v.Err
+		}
+	}
+
+	for start := 0; start < len(others); start += registerBatchSize {
+		batch := others[start:min(start+registerBatchSize, len(others))]
+		items := make([]DlmsLNRequestItem, len(batch))
+		for i, r := range batch {
+			items[i] = DlmsLNRequestItem{ClassId: ret[r].Entry.ClassId, Obis: ret[r].Entry.Obis, Attribute: 2}
+		}
+		data, err := d.Get(items)
+		for i, r := range batch {
+			if err != nil {
+				ret[r].Err = err
+				continue
+			}
+			ret[r].Value = data[i]
+			if e, ok := data[i].ResultError(); ok {
+				ret[r].Err = e
+			}
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,93 @@
+package dlmsal
+
+import "fmt"
+
+// splits profile generic buffer into rows, buffer can be array of structures or compact array of structures (bandwidth saving meters),
+// both give the same row shape
+func ProfileRows(buffer DlmsData) ([][]DlmsData, error) {
+	var items []DlmsData
+	switch buffer.Tag {
+	case TagArray:
+		v, ok := buffer.Value.([]DlmsData)
+		if !ok {
+			return nil, fmt.Errorf("unexpected array type %T", buffer.Value)
+		}
+		items = v
+	case TagCompactArray:
+		v, ok := buffer.Value.(DlmsCompactArray)
+		if !ok {
+			return nil, fmt.Errorf("unexpected compact array type %T", buffer.Value)
+		}
+		if v.tag != TagStructure {
+			return nil, fmt.Errorf("compact array doesnt contain structures but %d", v.tag)
+		}
+		items = v.value
+	default:
+		return nil, fmt.Errorf("unexpected buffer tag %d", buffer.Tag)
+	}
+
+	rows := make([][]DlmsData, len(items))
+	for i, it := range items {
+		if it.Tag != TagStructure {
+			return nil, fmt.Errorf("row %d isnt structure but %d", i, it.Tag)
+		}
+		r, ok := it.Value.([]DlmsData)
+		if !ok {
+			return nil, fmt.Errorf("unexpected row %d type %T", i, it.Value)
+		}
+		rows[i] = r
+	}
+	return rows, nil
+}
+
+type SortMethod uint8
+
+const (
+	SortMethodFifo           SortMethod = 1
+	SortMethodLifo           SortMethod = 2
+	SortMethodLargest        SortMethod = 3
+	SortMethodSmallest       SortMethod = 4
+	SortMethodNearestToZero  SortMethod = 5
+	SortMethodFarestFromZero SortMethod = 6
+)
+
+const (
+	profileGenericClassId     = 7
+	profileAttrCapturePeriod  = 4
+	profileAttrSortMethod     = 5
+	profileAttrEntriesInUse   = 7
+	profileAttrProfileEntries = 8
+)
+
+type ProfileInfo struct {
+	CapturePeriod  uint32 // seconds, zero means asynchronous capturing
+	SortMethod     SortMethod
+	EntriesInUse   uint32
+	ProfileEntries uint32
+}
+
+// reads capture_period, sort_method, entries_in_use and profile_entries of profile generic in one request
+func (d *dlmsal) ProfileInfo(obis DlmsObis) (ProfileInfo, error) {
+	var info ProfileInfo
+	attrs := []int8{profileAttrCapturePeriod, profileAttrSortMethod, profileAttrEntriesInUse, profileAttrProfileEntries}
+	items := make([]DlmsLNRequestItem, len(attrs))
+	for i, a := range attrs {
+		items[i] = DlmsLNRequestItem{ClassId: profileGenericClassId, Obis: obis, Attribute: a}
+	}
+	data, err := d.Get(items)
+	if err != nil {
+		return info, err
+	}
+	var sm uint8
+	targets := []any{&info.CapturePeriod, &sm, &info.EntriesInUse, &info.ProfileEntries}
+	for i := range data {
+		if e, ok := data[i].ResultError(); ok {
+			return info, e
+		}
+		if err = Cast(targets[i], data[i]); err != nil {
+			return info, fmt.Errorf("unable to cast profile attribute %d: %w", attrs[i], err)
+		}
+	}
+	info.SortMethod = SortMethod(sm)
+	return info, nil
+}
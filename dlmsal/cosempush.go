@@ -0,0 +1,199 @@
+package dlmsal
+
+import (
+	"fmt"
+)
+
+// transport_service of send_destination_and_method
+type TransportService byte
+
+const (
+	TransportServiceTcp    TransportService = 0
+	TransportServiceUdp    TransportService = 1
+	TransportServiceFtp    TransportService = 2
+	TransportServiceSmtp   TransportService = 3
+	TransportServiceSms    TransportService = 4
+	TransportServiceHdlc   TransportService = 5
+	TransportServiceMbus   TransportService = 6
+	TransportServiceZigBee TransportService = 7
+)
+
+type PushMessage byte
+
+const (
+	PushMessageApdu PushMessage = 0 // a-xdr encoded cosem apdu
+	PushMessageXml  PushMessage = 1
+)
+
+// element of push_object_list, the same as capture object of profile generic
+type PushObject struct {
+	ClassId   uint16
+	Obis      DlmsObis
+	Attribute int8
+	DataIndex uint16 // zero means whole attribute
+}
+
+type PushWindow struct {
+	Start DlmsDateTime
+	End   DlmsDateTime
+}
+
+type PushConfig struct {
+	Objects                    []PushObject
+	Service                    TransportService
+	Destination                []byte // e.g. "host:port" for tcp/udp, phone number for sms
+	Message                    PushMessage
+	Windows                    []PushWindow // empty means push is allowed anytime
+	RandomisationStartInterval uint16       // seconds
+	NumberOfRetries            uint8
+	RepetitionDelay            uint16 // seconds
+}
+
+const (
+	pushSetupClassId             = 40
+	pushSetupAttrObjectList      = 2
+	pushSetupAttrDestination     = 3
+	pushSetupAttrWindow          = 4
+	pushSetupAttrRandomisation   = 5
+	pushSetupAttrRetries         = 6
+	pushSetupAttrRepetitionDelay = 7
+)
+
+// writes attributes 2-7 of push setup (class 40) in a single set
+func (d *dlmsal) ConfigurePush(obis DlmsObis, cfg PushConfig) error {
+	values := NewPushSetupData(cfg)
+	items := make([]DlmsLNRequestItem, len(values))
+	for i := range values {
+		items[i] = DlmsLNRequestItem{ClassId: pushSetupClassId, Obis: obis, Attribute: int8(pushSetupAttrObjectList + i), SetData: &values[i]}
+	}
+	ret, err := d.Set(items)
+	if err != nil {
+		return err
+	}
+	for i, r := range ret {
+		if r != TagResultSuccess {
+			return fmt.Errorf("unable to set push setup attribute %d: %w", pushSetupAttrObjectList+i, NewDlmsError(r))
+		}
+	}
+	return nil
+}
+
+// reads attributes 2-7 of push setup
+func (d *dlmsal) ReadPushSetup(obis DlmsObis) (cfg PushConfig, err error) {
+	items := make([]DlmsLNRequestItem, pushSetupAttrRepetitionDelay-pushSetupAttrObjectList+1)
+	for i := range items {
+		items[i] = DlmsLNRequestItem{ClassId: pushSetupClassId, Obis: obis, Attribute: int8(pushSetupAttrObjectList + i)}
+	}
+	data, err := d.Get(items)
+	if err != nil {
+		return
+	}
+	for i := range data {
+		if e, ok := data[i].ResultError(); ok {
+			return cfg, fmt.Errorf("unable to get push setup attribute %d: %w", pushSetupAttrObjectList+i, e)
+		}
+	}
+	return ParsePushSetup(data)
+}
+
+// values of attributes 2-7 in this order
+func NewPushSetupData(cfg PushConfig) []DlmsData {
+	objects := make([]DlmsData, len(cfg.Objects))
+	for i, o := range cfg.Objects {
+		objects[i] = DlmsData{Tag: TagStructure, Value: []DlmsData{
+			{Tag: TagLongUnsigned, Value: o.ClassId},
+			{Tag: TagOctetString, Value: o.Obis},
+			{Tag: TagInteger, Value: o.Attribute},
+			{Tag: TagLongUnsigned, Value: o.DataIndex},
+		}}
+	}
+	windows := make([]DlmsData, len(cfg.Windows))
+	for i, w := range cfg.Windows {
+		windows[i] = DlmsData{Tag: TagStructure, Value: []DlmsData{
+			{Tag: TagOctetString, Value: w.Start},
+			{Tag: TagOctetString, Value: w.End},
+		}}
+	}
+	return []DlmsData{
+		{Tag: TagArray, Value: objects},
+		{Tag: TagStructure, Value: []DlmsData{
+			{Tag: TagEnum, Value: DlmsEnum(cfg.Service)},
+			{Tag: TagOctetString, Value: cfg.Destination},
+			{Tag: TagEnum, Value: DlmsEnum(cfg.Message)},
+		}},
+		{Tag: TagArray, Value: windows},
+		{Tag: TagLongUnsigned, Value: cfg.RandomisationStartInterval},
+		{Tag: TagUnsigned, Value: cfg.NumberOfRetries},
+		{Tag: TagLongUnsigned, Value: cfg.RepetitionDelay},
+	}
+}
+
+// inverse of NewPushSetupData, data are values of attributes 2-7
+func ParsePushSetup(data []DlmsData) (cfg PushConfig, err error) {
+	if len(data) != 6 {
+		return cfg, fmt.Errorf("expected 6 push setup attributes, got %d", len(data))
+	}
+	objects, err := calarray(data[0])
+	if err != nil {
+		return cfg, fmt.Errorf("invalid push object list: %w", err)
+	}
+	cfg.Objects = make([]PushObject, len(objects))
+	for i, o := range objects {
+		str, err := calstructure(o, 4)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid push object %d: %w", i, err)
+		}
+		po := &cfg.Objects[i]
+		for j, t := range []any{&po.ClassId, &po.Obis, &po.Attribute, &po.DataIndex} {
+			if err = Cast(t, str[j]); err != nil {
+				return cfg, fmt.Errorf("invalid push object %d: %w", i, err)
+			}
+		}
+	}
+
+	dest, err := calstructure(data[1], 3)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid send destination and method: %w", err)
+	}
+	var service, message uint8
+	if err = Cast(&service, dest[0]); err != nil {
+		return cfg, fmt.Errorf("invalid transport service: %w", err)
+	}
+	if cfg.Destination, err = calbytes(dest[1]); err != nil {
+		return cfg, fmt.Errorf("invalid destination: %w", err)
+	}
+	if err = Cast(&message, dest[2]); err != nil {
+		return cfg, fmt.Errorf("invalid message type: %w", err)
+	}
+	cfg.Service = TransportService(service)
+	cfg.Message = PushMessage(message)
+
+	windows, err := calarray(data[2])
+	if err != nil {
+		return cfg, fmt.Errorf("invalid communication window: %w", err)
+	}
+	cfg.Windows = make([]PushWindow, len(windows))
+	for i, w := range windows {
+		str, err := calstructure(w, 2)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid communication window %d: %w", i, err)
+		}
+		if err = Cast(&cfg.Windows[i].Start, str[0]); err != nil {
+			return cfg, fmt.Errorf("invalid start of communication window %d: %w", i, err)
+		}
+		if err = Cast(&cfg.Windows[i].End, str[1]); err != nil {
+			return cfg, fmt.Errorf("invalid end of communication window %d: %w", i, err)
+		}
+	}
+
+	if err = Cast(&cfg.RandomisationStartInterval, data[3]); err != nil {
+		return cfg, fmt.Errorf("invalid randomisation start interval: %w", err)
+	}
+	if err = Cast(&cfg.NumberOfRetries, data[4]); err != nil {
+		return cfg, fmt.Errorf("invalid number of retries: %w", err)
+	}
+	if err = Cast(&cfg.RepetitionDelay, data[5]); err != nil {
+		return cfg, fmt.Errorf("invalid repetition delay: %w", err)
+	}
+	return cfg, nil
+}
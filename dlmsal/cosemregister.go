@@ -0,0 +1,174 @@
+package dlmsal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+const registerBatchSize = 10 // items (values and scaler_units together) in a single get-with-list, meters usually dont like long lists
+
+type ScaledValue struct {
+	Obis   DlmsObis
+	Raw    DlmsData // attribute 2 as received
+	Value  float64  // raw value multiplied by 10^scaler
+	Scaler int8
+	Unit   byte // use GetUnit for the text
+	Err    error
+}
+
+// exact decimal value of integer registers, float64 Value loses precision beyond 2^53 which matters for big energy counters,
+// fractional digits are kept according to the scaler (1230 with scaler -3 is 1.230), floats are formatted from Value,
+// empty string in case of error
+func (s ScaledValue) DecimalString() string {
+	if s.Err != nil {
+		return ""
+	}
+	neg, mag, ok := rawinteger(s.Raw)
+	if !ok {
+		return strconv.FormatFloat(s.Value, 'f', -1, 64)
+	}
+	digits := strconv.FormatUint(mag, 10)
+	switch {
+	case s.Scaler > 0 && mag != 0:
+		digits += strings.Repeat("0", int(s.Scaler))
+	case s.Scaler < 0:
+		n := -int(s.Scaler)
+		if len(digits) <= n {
+			digits = strings.Repeat("0", n-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-n] + "." + digits[len(digits)-n:]
+	}
+	if neg && mag != 0 {
+		return "-" + digits
+	}
+	return digits
+}
+
+// sign and magnitude, int64 minimum has no positive counterpart, so magnitude is unsigned
+func rawinteger(d DlmsData) (neg bool, mag uint64, ok bool) {
+	var v int64
+	switch t := d.Value.(type) {
+	case uint8:
+		return false, uint64(t), true
+	case uint16:
+		return false, uint64(t), true
+	case uint32:
+		return false, uint64(t), true
+	case uint64:
+		return false, t, true
+	case DlmsEnum:
+		return false, uint64(t), true
+	case int8:
+		v = int64(t)
+	case int16:
+		v = int64(t)
+	case int32:
+		v = int64(t)
+	case int64:
+		v = t
+	default:
+		return false, 0, false
+	}
+	if v < 0 {
+		return true, uint64(-(v + 1)) + 1, true
+	}
+	return false, uint64(v), true
+}
+
+type scalerunit struct {
+	Scaler int8
+	Unit   byte
+}
+
+// reads value of register objects (class 3), scaler_unit is read only once per object and then taken from cache,
+// cache is valid till the next Open, errors of single registers are in ScaledValue.Err
+// registers are split into get-with-list requests of at most registerBatchSize items counting also the scaler_unit reads,
+// there is no generic batched get in this package so splitting is done here
+func (d *dlmsal) ReadRegisters(obisList []DlmsObis) ([]ScaledValue, error) {
+	if !d.isopen {
+		return nil, base.ErrNotOpened
+	}
+	if d.scalers == nil {
+		d.scalers = make(map[DlmsObis]scalerunit)
+	}
+
+	ret := make([]ScaledValue, len(obisList))
+	for start, end := 0, 0; start < len(obisList); start = end {
+		// take registers while the value plus a not yet known scaler fit into the limit
+		missing := make(map[DlmsObis]int)
+		count := 0
+		for end = start; end < len(obisList); end++ {
+			o := obisList[end]
+			cost := 1
+			if _, ok := d.scalers[o]; !ok {
+				if _, ok := missing[o]; !ok {
+					cost++
+				}
+			}
+			if end > start && count+cost > registerBatchSize {
+				break
+			}
+			count += cost
+			if cost > 1 {
+				missing[o] = 0
+			}
+		}
+		// values first, missing scalers appended behind them
+		items := make([]DlmsLNRequestItem, 0, count)
+		for _, o := range obisList[start:end] {
+			items = append(items, DlmsLNRequestItem{ClassId: 3, Obis: o, Attribute: 2})
+		}
+		for _, o := range obisList[start:end] {
+			if i, ok := missing[o]; ok && i == 0 {
+				missing[o] = len(items)
+				items = append(items, DlmsLNRequestItem{ClassId: 3, Obis: o, Attribute: 3})
+			}
+		}
+
+		data, err := d.Get(items)
+		if err != nil {
+			return nil, err
+		}
+		scalererr := make(map[DlmsObis]error)
+		for o, i := range missing {
+			if e, ok := data[i].ResultError(); ok {
+				scalererr[o] = e
+				continue
+			}
+			var su scalerunit
+			if err = Cast(&su, data[i]); err != nil {
+				scalererr[o] = err
+				continue
+			}
+			d.scalers[o] = su
+		}
+
+		for i, o := range obisList[start:end] {
+			r := &ret[start+i]
+			r.Obis = o
+			r.Raw = data[i]
+			if e, ok := data[i].ResultError(); ok {
+				r.Err = e
+				continue
+			}
+			su, ok := d.scalers[o]
+			if !ok {
+				r.Err = fmt.Errorf("unable to read scaler_unit of %s: %w", o.String(), scalererr[o])
+				continue
+			}
+			r.Scaler = su.Scaler
+			r.Unit = su.Unit
+			var v float64
+			if err = Cast(&v, data[i]); err != nil {
+				r.Err = fmt.Errorf("value of %s is not a number: %w", o.String(), err)
+				continue
+			}
+			r.Value = v * math.Pow10(int(su.Scaler))
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,118 @@
+package dlmsal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// security_policy of security setup (class 64) version 1, version 0 values are translated to these flags
+type SecurityPolicy byte
+
+const (
+	SecurityPolicyAuthenticatedRequest  SecurityPolicy = 0x04
+	SecurityPolicyEncryptedRequest      SecurityPolicy = 0x08
+	SecurityPolicySignedRequest         SecurityPolicy = 0x10
+	SecurityPolicyAuthenticatedResponse SecurityPolicy = 0x20
+	SecurityPolicyEncryptedResponse     SecurityPolicy = 0x40
+	SecurityPolicySignedResponse        SecurityPolicy = 0x80
+)
+
+func (p SecurityPolicy) Has(f SecurityPolicy) bool {
+	return p&f == f
+}
+
+func (p SecurityPolicy) String() string {
+	if p == 0 {
+		return "nothing"
+	}
+	var s []string
+	for _, f := range []struct {
+		f SecurityPolicy
+		n string
+	}{
+		{SecurityPolicyAuthenticatedRequest, "authenticated request"},
+		{SecurityPolicyEncryptedRequest, "encrypted request"},
+		{SecurityPolicySignedRequest, "signed request"},
+		{SecurityPolicyAuthenticatedResponse, "authenticated response"},
+		{SecurityPolicyEncryptedResponse, "encrypted response"},
+		{SecurityPolicySignedResponse, "signed response"},
+	} {
+		if p.Has(f.f) {
+			s = append(s, f.n)
+		}
+	}
+	return strings.Join(s, ", ")
+}
+
+type SecuritySuite byte
+
+const (
+	SecuritySuiteAesGcm128          SecuritySuite = 0
+	SecuritySuiteEcdhEcdsaAesGcm128 SecuritySuite = 1 // with sha-256
+	SecuritySuiteEcdhEcdsaAesGcm256 SecuritySuite = 2 // with sha-384
+)
+
+func (s SecuritySuite) String() string {
+	switch s {
+	case SecuritySuiteAesGcm128:
+		return "aes-gcm-128"
+	case SecuritySuiteEcdhEcdsaAesGcm128:
+		return "ecdh-ecdsa-aes-gcm-128-sha-256"
+	case SecuritySuiteEcdhEcdsaAesGcm256:
+		return "ecdh-ecdsa-aes-gcm-256-sha-384"
+	}
+	return fmt.Sprintf("unknown security suite %d", byte(s))
+}
+
+type SecuritySetup struct {
+	Policy SecurityPolicy
+	Suite  SecuritySuite
+}
+
+const (
+	securitySetupClassId    = 64
+	securitySetupAttrPolicy = 2
+	securitySetupAttrSuite  = 3
+)
+
+// reads security_policy and security_suite of the security setup object in a single get
+func (d *dlmsal) ReadSecuritySetup(obis DlmsObis) (ret SecuritySetup, err error) {
+	data, err := d.Get([]DlmsLNRequestItem{
+		{ClassId: securitySetupClassId, Obis: obis, Attribute: securitySetupAttrPolicy},
+		{ClassId: securitySetupClassId, Obis: obis, Attribute: securitySetupAttrSuite},
+	})
+	if err != nil {
+		return
+	}
+	for _, v := range data {
+		if e, ok := v.ResultError(); ok {
+			return ret, e
+		}
+	}
+	var p, s uint8
+	if err = Cast(&p, data[0]); err != nil {
+		return ret, fmt.Errorf("invalid security policy: %w", err)
+	}
+	if err = Cast(&s, data[1]); err != nil {
+		return ret, fmt.Errorf("invalid security suite: %w", err)
+	}
+	ret.Policy = securitypolicy(p)
+	ret.Suite = SecuritySuite(s)
+	return
+}
+
+// version 0 policy is enum 0-3 (nothing, authenticated, encrypted, both) applied to all messages,
+// version 1 doesnt use the lowest two bits, so they can be told apart
+func securitypolicy(p uint8) SecurityPolicy {
+	if p&^3 != 0 {
+		return SecurityPolicy(p)
+	}
+	var ret SecurityPolicy
+	if p&1 != 0 {
+		ret |= SecurityPolicyAuthenticatedRequest | SecurityPolicyAuthenticatedResponse
+	}
+	if p&2 != 0 {
+		ret |= SecurityPolicyEncryptedRequest | SecurityPolicyEncryptedResponse
+	}
+	return ret
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"reflect"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -53,12 +54,139 @@ func NewDlmsDataError(err DlmsResultTag) DlmsData {
 	return DlmsData{Tag: TagError, Value: NewDlmsError(err)}
 }
 
+// null-data, e.g. for clearing/resetting attribute using set, encoded just as a single zero tag byte
+func NewDlmsDataNull() DlmsData {
+	return DlmsData{Tag: TagNull}
+}
+
+// picks tag according to go type, for exact control build DlmsData directly:
+// nil -> null, bool -> boolean, int8 -> integer, int16 -> long, int32 -> double long, int64 and int -> long64,
+// uint8 -> unsigned, uint16 -> long unsigned, uint32 -> double long unsigned, uint64 and uint -> long64 unsigned, DlmsEnum -> enum,
+// float32 -> float32, float64 -> float64, string -> visible string, []byte and DlmsObis -> octet string,
+// time.Time (converted to DlmsDateTime) and DlmsDateTime -> date time, DlmsDate -> date, DlmsTime -> time, DlmsData is returned as is,
+// pointers are dereferenced and any other slice or array (also []any) -> array of recursively converted items
+func NewDlmsData(v any) (DlmsData, error) {
+	switch t := v.(type) {
+	case nil:
+		return DlmsData{Tag: TagNull}, nil
+	case DlmsData:
+		return t, nil
+	case bool:
+		return DlmsData{Tag: TagBoolean, Value: t}, nil
+	case int8:
+		return DlmsData{Tag: TagInteger, Value: t}, nil
+	case int16:
+		return DlmsData{Tag: TagLong, Value: t}, nil
+	case int32:
+		return DlmsData{Tag: TagDoubleLong, Value: t}, nil
+	case int64:
+		return DlmsData{Tag: TagLong64, Value: t}, nil
+	case int:
+		return DlmsData{Tag: TagLong64, Value: int64(t)}, nil
+	case uint8:
+		return DlmsData{Tag: TagUnsigned, Value: t}, nil
+	case uint16:
+		return DlmsData{Tag: TagLongUnsigned, Value: t}, nil
+	case uint32:
+		return DlmsData{Tag: TagDoubleLongUnsigned, Value: t}, nil
+	case uint64:
+		return DlmsData{Tag: TagLong64Unsigned, Value: t}, nil
+	case uint:
+		return DlmsData{Tag: TagLong64Unsigned, Value: uint64(t)}, nil
+	case DlmsEnum:
+		return DlmsData{Tag: TagEnum, Value: t}, nil
+	case float32:
+		return DlmsData{Tag: TagFloat32, Value: t}, nil
+	case float64:
+		return DlmsData{Tag: TagFloat64, Value: t}, nil
+	case string:
+		return DlmsData{Tag: TagVisibleString, Value: t}, nil
+	case []byte:
+		return DlmsData{Tag: TagOctetString, Value: t}, nil
+	case DlmsObis:
+		return DlmsData{Tag: TagOctetString, Value: t}, nil
+	case time.Time:
+		return DlmsData{Tag: TagDateTime, Value: NewDlmsDateTimeFromTime(t)}, nil
+	case DlmsDateTime:
+		return DlmsData{Tag: TagDateTime, Value: t}, nil
+	case DlmsDate:
+		return DlmsData{Tag: TagDate, Value: t}, nil
+	case DlmsTime:
+		return DlmsData{Tag: TagTime, Value: t}, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return DlmsData{Tag: TagNull}, nil
+		}
+		return NewDlmsData(rv.Elem().Interface())
+	case reflect.Slice, reflect.Array:
+		items := make([]DlmsData, rv.Len())
+		for i := range items {
+			var err error
+			items[i], err = NewDlmsData(rv.Index(i).Interface())
+			if err != nil {
+				return DlmsData{}, fmt.Errorf("array item %d: %w", i, err)
+			}
+		}
+		return DlmsData{Tag: TagArray, Value: items}, nil
+	}
+	return DlmsData{}, fmt.Errorf("unsupported type %T", v)
+}
+
+// returns data access result error in case item failed, other errors under TagError are reported as other reason
+func (d DlmsData) ResultError() (*DlmsError, bool) {
+	if d.Tag != TagError {
+		return nil, false
+	}
+	switch e := d.Value.(type) {
+	case *DlmsError:
+		return e, true
+	case DlmsError:
+		return &e, true
+	}
+	return &DlmsError{Result: TagResultOtherReason}, true
+}
+
+// splits get results, both slices have the same length as data, so index still matches requested item,
+// values[i] is empty for failed item and errors[i] is nil for succeeded one
+func SplitResults(data []DlmsData) (values []DlmsData, errors []*DlmsError) {
+	values = make([]DlmsData, len(data))
+	errors = make([]*DlmsError, len(data))
+	for i, d := range data {
+		if e, ok := d.ResultError(); ok {
+			errors[i] = e
+		} else {
+			values[i] = d
+		}
+	}
+	return
+}
+
 type DlmsError struct {
-	Result DlmsResultTag
+	Result    DlmsResultTag
+	Exception *ExceptionError    // filled in case of exception-response
+	Item      *DlmsLNRequestItem // requested item which failed, filled by Get
 }
 
 func (e *DlmsError) Error() string {
-	return fmt.Sprintf("dlms error: %s", e.Result)
+	var item string
+	if e.Item != nil {
+		item = fmt.Sprintf(" for %s class %d attr %d", e.Item.Obis.String(), e.Item.ClassId, e.Item.Attribute)
+	}
+	if e.Exception != nil {
+		return fmt.Sprintf("dlms error: %s%s, %s", e.Result, item, e.Exception)
+	}
+	return fmt.Sprintf("dlms error: %s%s", e.Result, item)
+}
+
+func (e *DlmsError) Unwrap() error {
+	if e.Exception == nil {
+		return nil
+	}
+	return e.Exception
 }
 
 func NewDlmsError(result DlmsResultTag) error {
@@ -87,31 +215,71 @@ func decodeDataArray(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data Dlm
 	if err != nil {
 		return data, 0, err
 	}
-	d := make([]DlmsData, l)
-	for i := 0; i < int(l); i++ {
-		d[i], ii, err = decodeDataTag(src, tmpbuffer)
+	d := make([]DlmsData, 0, min(l, maxPrealloc)) // length comes from the wire, so grow as items really come
+	if l == 0 {
+		return DlmsData{Tag: tag, Value: d}, c, nil
+	}
+	// every item starts with tag, so for fixed size items read value together with the next tag,
+	// that halves reads for big profiles and avoids going through the whole decodeData switch
+	_, err = io.ReadFull(src, tmpbuffer[:1])
+	if err != nil {
+		return data, 0, err
+	}
+	c++
+	t := dataTag(tmpbuffer[0])
+	last := int(l) - 1
+	for i := 0; i <= last; i++ {
+		n, _ := fixedsize(t)
+		if n > 0 {
+			w := n
+			if i != last {
+				w++
+			}
+			_, err = io.ReadFull(src, tmpbuffer[:w])
+			if err != nil {
+				return data, 0, err
+			}
+			d = append(d, decodefixed(t, tmpbuffer[:n]))
+			c += w
+			if i != last {
+				t = dataTag(tmpbuffer[n])
+			}
+			continue
+		}
+
+		var item DlmsData
+		item, ii, err = decodeData(src, t, tmpbuffer)
 		if err != nil {
 			return data, 0, err
 		}
+		d = append(d, item)
 		c += ii
+		if i != last {
+			_, err = io.ReadFull(src, tmpbuffer[:1])
+			if err != nil {
+				return data, 0, err
+			}
+			c++
+			t = dataTag(tmpbuffer[0])
+		}
 	}
 	return DlmsData{Tag: tag, Value: d}, c, nil
 }
 
 func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData, c int, err error) {
+	if n, name := fixedsize(tag); n > 0 {
+		_, err = io.ReadFull(src, tmpbuffer[:n])
+		if err != nil {
+			return data, 0, fmt.Errorf("too short data for %s %w", name, err)
+		}
+		return decodefixed(tag, tmpbuffer[:n]), n, nil
+	}
+
 	switch tag {
 	case TagNull:
 		return DlmsData{Tag: tag}, 0, nil
 	case TagArray, TagStructure:
 		return decodeDataArray(src, tag, tmpbuffer)
-	case TagBoolean:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:1])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for boolean, %w", err)
-			}
-			return DlmsData{Tag: tag, Value: tmpbuffer[0] != 0}, 1, nil
-		}
 	case TagBitString:
 		{
 			l, c, err := decodelength(src, tmpbuffer)
@@ -121,11 +289,11 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 			blen := (l + 7) >> 3
 			var tmp []byte
 			if blen > uint(len(tmpbuffer)) {
-				tmp = make([]byte, blen)
+				tmp, err = readbytes(src, blen)
 			} else {
+				_, err = io.ReadFull(src, tmpbuffer[:blen])
 				tmp = tmpbuffer[:blen]
 			}
-			_, err = io.ReadFull(src, tmp)
 			if err != nil {
 				return data, 0, fmt.Errorf("too short data for bitstring %w", err)
 			}
@@ -143,32 +311,13 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 			}
 			return DlmsData{Tag: tag, Value: val}, c + int(blen), nil // this type is a bit questionable, better is maybe []bool ?, todo how to interpret that
 		}
-	case TagDoubleLong:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:4])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for double long %w", err)
-			}
-			v := int32(tmpbuffer[0])<<24 | int32(tmpbuffer[1])<<16 | int32(tmpbuffer[2])<<8 | int32(tmpbuffer[3])
-			return DlmsData{Tag: tag, Value: v}, 4, nil
-		}
-	case TagDoubleLongUnsigned:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:4])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for double long unsigned %w", err)
-			}
-			v := uint32(tmpbuffer[0])<<24 | uint32(tmpbuffer[1])<<16 | uint32(tmpbuffer[2])<<8 | uint32(tmpbuffer[3])
-			return DlmsData{Tag: tag, Value: v}, 4, nil
-		}
 	case TagOctetString:
 		{
 			l, c, err := decodelength(src, tmpbuffer)
 			if err != nil {
 				return data, 0, err
 			}
-			v := make([]byte, l)
-			_, err = io.ReadFull(src, v)
+			v, err := readbytes(src, l)
 			if err != nil {
 				return data, 0, fmt.Errorf("too short data for octet string %w", err)
 			}
@@ -180,8 +329,7 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 			if err != nil {
 				return data, 0, err
 			}
-			v := make([]byte, l)
-			_, err = io.ReadFull(src, v)
+			v, err := readbytes(src, l)
 			if err != nil {
 				return data, 0, fmt.Errorf("too short data for visible string %w", err)
 			}
@@ -205,54 +353,6 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 			}
 			return DlmsData{Tag: tag, Value: sb.String()}, c + int(l), nil
 		}
-	case TagBCD:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:1])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for bcd %w", err)
-			}
-			v := int(tmpbuffer[0]&0xf) + 10*(int(tmpbuffer[0]>>4)&7)
-			if (tmpbuffer[0] & 0x80) != 0 {
-				v = -v
-			}
-			return DlmsData{Tag: tag, Value: int8(v)}, 1, nil
-		}
-	case TagInteger:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:1])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for integer %w", err)
-			}
-			v := int8(tmpbuffer[0])
-			return DlmsData{Tag: tag, Value: v}, 1, nil
-		}
-	case TagLong:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:2])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for long %w", err)
-			}
-			v := int16(tmpbuffer[0])<<8 | int16(tmpbuffer[1])
-			return DlmsData{Tag: tag, Value: v}, 2, nil
-		}
-	case TagUnsigned, TagEnum:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:1])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for unsigned/enum %w", err)
-			}
-			v := uint8(tmpbuffer[0])
-			return DlmsData{Tag: tag, Value: v}, 1, nil
-		}
-	case TagLongUnsigned:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:2])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for long unsigned %w", err)
-			}
-			v := uint16(tmpbuffer[0])<<8 | uint16(tmpbuffer[1])
-			return DlmsData{Tag: tag, Value: v}, 2, nil
-		}
 	case TagCompactArray:
 		{
 			n, err := io.ReadFull(src, tmpbuffer[:1])
@@ -269,11 +369,11 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 				n += c
 				var tmp []byte
 				if uint(len(tmpbuffer)) < l {
-					tmp = make([]byte, l)
+					tmp, err = readbytes(src, l)
 				} else {
+					_, err = io.ReadFull(src, tmpbuffer[:l])
 					tmp = tmpbuffer[:l]
 				}
-				_, err = io.ReadFull(src, tmp)
 				if err != nil {
 					return data, 0, fmt.Errorf("too short data for compact array (number of structure items), %w", err)
 				}
@@ -282,6 +382,16 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 					types[i] = dataTag(tmp[i])
 				}
 				n += int(l)
+			} else if ctag == TagArray { // variable length inner arrays, only inner item type is here, length is per element
+				_, err = io.ReadFull(src, tmpbuffer[:1])
+				if err != nil {
+					return data, 0, fmt.Errorf("too short data for compact array (inner array type), %w", err)
+				}
+				n++
+				if dataTag(tmpbuffer[0]) == TagNull {
+					return data, 0, fmt.Errorf("unable to decode compact array with null inner array tag")
+				}
+				types = []dataTag{dataTag(tmpbuffer[0])}
 			} else { // just bunch of items
 				if ctag == TagNull {
 					return data, 0, fmt.Errorf("unable to decode compact array with null tag")
@@ -331,6 +441,25 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 						rem -= c
 					}
 					items = append(items, DlmsData{Tag: TagStructure, Value: str})
+				} else if ctag == TagArray {
+					il, c, err := decodelength(cntstr, tmpbuffer)
+					if err != nil {
+						return data, 0, fmt.Errorf("too short data for compact array (inner array length) %w", err)
+					}
+					rem -= c
+					arr := make([]DlmsData, 0, min(il, maxPrealloc))
+					for i := uint(0); i < il; i++ {
+						if rem <= 0 {
+							return data, 0, fmt.Errorf("there are no bytes left for another inner array item")
+						}
+						item, c, err := decodeData(cntstr, types[0], tmpbuffer)
+						if err != nil {
+							return data, 0, err
+						}
+						arr = append(arr, item)
+						rem -= c
+					}
+					items = append(items, DlmsData{Tag: TagArray, Value: arr})
 				} else {
 					data, c, err := decodeData(cntstr, ctag, tmpbuffer)
 					if err != nil {
@@ -340,100 +469,118 @@ func decodeData(src io.Reader, tag dataTag, tmpbuffer *tmpbuffer) (data DlmsData
 					items = append(items, data)
 				}
 			}
-			toret := DlmsCompactArray{tag: ctag, value: items}
-			if ctag == TagStructure {
-				toret.tags = types
-			}
+			toret := DlmsCompactArray{tag: ctag, tags: types, value: items}
 			return DlmsData{Tag: tag, Value: toret}, n, nil
 		}
+	}
+	return data, 0, fmt.Errorf("unknown tag %d", tag)
+}
+
+// standard data tags, dont care is left out
+func isdatatag(b byte) bool {
+	switch dataTag(b) {
+	case TagNull, TagArray, TagStructure, TagBoolean, TagBitString, TagDoubleLong, TagDoubleLongUnsigned, TagFloatingPoint,
+		TagOctetString, TagVisibleString, TagUTF8String, TagBCD, TagInteger, TagLong, TagUnsigned, TagLongUnsigned,
+		TagCompactArray, TagLong64, TagLong64Unsigned, TagEnum, TagFloat32, TagFloat64, TagDateTime, TagDate, TagTime:
+		return true
+	}
+	return false
+}
+
+// fixed size types, name is there just for error messages
+func fixedsize(tag dataTag) (int, string) {
+	switch tag {
+	case TagBoolean:
+		return 1, "boolean"
+	case TagBCD:
+		return 1, "bcd"
+	case TagInteger:
+		return 1, "integer"
+	case TagUnsigned, TagEnum:
+		return 1, "unsigned/enum"
+	case TagLong:
+		return 2, "long"
+	case TagLongUnsigned:
+		return 2, "long unsigned"
+	case TagDoubleLong:
+		return 4, "double long"
+	case TagDoubleLongUnsigned:
+		return 4, "double long unsigned"
+	case TagFloat32, TagFloatingPoint:
+		return 4, "float32"
+	case TagTime:
+		return 4, "time"
+	case TagDate:
+		return 5, "date"
 	case TagLong64:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:8])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for long64 %w", err)
-			}
-			v := int64(tmpbuffer[0])<<56 | int64(tmpbuffer[1])<<48 | int64(tmpbuffer[2])<<40 | int64(tmpbuffer[3])<<32 | int64(tmpbuffer[4])<<24 | int64(tmpbuffer[5])<<16 | int64(tmpbuffer[6])<<8 | int64(tmpbuffer[7])
-			return DlmsData{Tag: tag, Value: v}, 8, nil
-		}
+		return 8, "long64"
 	case TagLong64Unsigned:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:8])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for long64 unsigned %w", err)
-			}
-			v := uint64(tmpbuffer[0])<<56 | uint64(tmpbuffer[1])<<48 | uint64(tmpbuffer[2])<<40 | uint64(tmpbuffer[3])<<32 | uint64(tmpbuffer[4])<<24 | uint64(tmpbuffer[5])<<16 | uint64(tmpbuffer[6])<<8 | uint64(tmpbuffer[7])
-			return DlmsData{Tag: tag, Value: v}, 8, nil
-		}
-	case TagFloat32, TagFloatingPoint:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:4])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for float32 %w", err)
-			}
-			return DlmsData{Tag: tag, Value: math.Float32frombits(binary.BigEndian.Uint32(tmpbuffer[:4]))}, 4, nil
-		}
+		return 8, "long64 unsigned"
 	case TagFloat64:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:8])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for float64 %w", err)
-			}
-			return DlmsData{Tag: tag, Value: math.Float64frombits(binary.BigEndian.Uint64(tmpbuffer[:8]))}, 8, nil
-		}
+		return 8, "float64"
 	case TagDateTime:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:12])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for datetime %w", err)
-			}
-			v := DlmsDateTime{
-				Date: DlmsDate{
-					Year:      uint16(tmpbuffer[0])<<8 | uint16(tmpbuffer[1]),
-					Month:     tmpbuffer[2],
-					Day:       tmpbuffer[3],
-					DayOfWeek: tmpbuffer[4],
-				},
-				Time: DlmsTime{
-					Hour:       tmpbuffer[5],
-					Minute:     tmpbuffer[6],
-					Second:     tmpbuffer[7],
-					Hundredths: tmpbuffer[8],
-				},
-				Deviation: int16(tmpbuffer[9])<<8 | int16(tmpbuffer[10]), // signed
-				Status:    tmpbuffer[11],
-			}
-			return DlmsData{Tag: tag, Value: v}, 12, nil
-		}
-	case TagDate:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:5])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for date %w", err)
-			}
-			v := DlmsDate{
-				Year:      uint16(tmpbuffer[0])<<8 | uint16(tmpbuffer[1]),
-				Month:     tmpbuffer[2],
-				Day:       tmpbuffer[3],
-				DayOfWeek: tmpbuffer[4],
-			}
-			return DlmsData{Tag: tag, Value: v}, 5, nil
+		return 12, "datetime"
+	}
+	return 0, ""
+}
+
+// b has to be exactly fixedsize long
+func decodefixed(tag dataTag, b []byte) DlmsData {
+	switch tag {
+	case TagBoolean:
+		return DlmsData{Tag: tag, Value: b[0] != 0}
+	case TagBCD:
+		v := int(b[0]&0xf) + 10*(int(b[0]>>4)&7)
+		if (b[0] & 0x80) != 0 {
+			v = -v
 		}
+		return DlmsData{Tag: tag, Value: int8(v)}
+	case TagInteger:
+		return DlmsData{Tag: tag, Value: int8(b[0])}
+	case TagUnsigned:
+		return DlmsData{Tag: tag, Value: b[0]}
+	case TagEnum:
+		return DlmsData{Tag: tag, Value: DlmsEnum(b[0])}
+	case TagLong:
+		return DlmsData{Tag: tag, Value: int16(binary.BigEndian.Uint16(b))}
+	case TagLongUnsigned:
+		return DlmsData{Tag: tag, Value: binary.BigEndian.Uint16(b)}
+	case TagDoubleLong:
+		return DlmsData{Tag: tag, Value: int32(binary.BigEndian.Uint32(b))}
+	case TagDoubleLongUnsigned:
+		return DlmsData{Tag: tag, Value: binary.BigEndian.Uint32(b)}
+	case TagFloat32, TagFloatingPoint:
+		return DlmsData{Tag: tag, Value: math.Float32frombits(binary.BigEndian.Uint32(b))}
+	case TagLong64:
+		return DlmsData{Tag: tag, Value: int64(binary.BigEndian.Uint64(b))}
+	case TagLong64Unsigned:
+		return DlmsData{Tag: tag, Value: binary.BigEndian.Uint64(b)}
+	case TagFloat64:
+		return DlmsData{Tag: tag, Value: math.Float64frombits(binary.BigEndian.Uint64(b))}
 	case TagTime:
-		{
-			_, err = io.ReadFull(src, tmpbuffer[:4])
-			if err != nil {
-				return data, 0, fmt.Errorf("too short data for time %w", err)
-			}
-			v := DlmsTime{
-				Hour:       tmpbuffer[0],
-				Minute:     tmpbuffer[1],
-				Second:     tmpbuffer[2],
-				Hundredths: tmpbuffer[3],
-			}
-			return DlmsData{Tag: tag, Value: v}, 4, nil
+		return DlmsData{Tag: tag, Value: DlmsTime{Hour: b[0], Minute: b[1], Second: b[2], Hundredths: b[3]}}
+	case TagDate:
+		return DlmsData{Tag: tag, Value: DlmsDate{Year: binary.BigEndian.Uint16(b), Month: b[2], Day: b[3], DayOfWeek: b[4]}}
+	case TagDateTime:
+		v := DlmsDateTime{
+			Date: DlmsDate{
+				Year:      binary.BigEndian.Uint16(b),
+				Month:     b[2],
+				Day:       b[3],
+				DayOfWeek: b[4],
+			},
+			Time: DlmsTime{
+				Hour:       b[5],
+				Minute:     b[6],
+				Second:     b[7],
+				Hundredths: b[8],
+			},
+			Deviation: int16(binary.BigEndian.Uint16(b[9:])), // signed
+			Status:    b[11],
 		}
+		return DlmsData{Tag: tag, Value: v}
 	}
-	return data, 0, fmt.Errorf("unknown tag %d", tag)
+	return DlmsData{Tag: TagError, Value: fmt.Errorf("not a fixed size tag %d", tag)}
 }
 
 func EncodeData(d DlmsData) ([]byte, error) {
@@ -445,6 +592,35 @@ func EncodeData(d DlmsData) ([]byte, error) {
 	return out.Bytes(), nil
 }
 
+// encodes d, decodes it back and encodes the result again, error when the two encodings differ or not everything was consumed,
+// returned data are in the decoder representation, so go types can differ from d while still the same on the wire:
+// time.Time and *DlmsDateTime come back as DlmsDateTime, DlmsObis in octet string as []byte, bitstring string as []bool,
+// []*DlmsData as []DlmsData, boolean/enum/integers given as other go int types as their natural type,
+// inherently lossy are float64 stored as float32/floating-point and bcd which holds just -79..79
+func EncodeDecodeRoundTrip(d DlmsData) (DlmsData, error) {
+	b, err := EncodeData(d)
+	if err != nil {
+		return DlmsData{}, err
+	}
+	var tmp tmpbuffer
+	r := bytes.NewReader(b)
+	ret, c, err := decodeDataTag(r, &tmp)
+	if err != nil {
+		return ret, fmt.Errorf("unable to decode encoded data: %w", err)
+	}
+	if c != len(b) || r.Len() != 0 {
+		return ret, fmt.Errorf("decoded %d bytes of %d", c, len(b))
+	}
+	b2, err := EncodeData(ret)
+	if err != nil {
+		return ret, fmt.Errorf("unable to encode decoded data: %w", err)
+	}
+	if !bytes.Equal(b, b2) {
+		return ret, fmt.Errorf("round trip mismatch, %x became %x", b, b2)
+	}
+	return ret, nil
+}
+
 func encodeData(out *bytes.Buffer, d *DlmsData) error {
 	if d == nil {
 		return fmt.Errorf("nil data") // no panic here
@@ -455,7 +631,7 @@ func encodeData(out *bytes.Buffer, d *DlmsData) error {
 
 func encodeDatanoTag(out *bytes.Buffer, d *DlmsData) error {
 	switch d.Tag {
-	case TagNull:
+	case TagNull: // null-data has no content, value is ignored
 	case TagArray, TagStructure:
 		return encodeArrayStructure(out, d)
 	case TagBitString:
@@ -544,6 +720,9 @@ func encodeCompactArray(out *bytes.Buffer, d *DlmsData) (err error) {
 	if input.tag == TagStructure && input.tags == nil {
 		return fmt.Errorf("no structure tags provided")
 	}
+	if input.tag == TagArray && len(input.tags) != 1 {
+		return fmt.Errorf("exactly one inner array tag has to be provided")
+	}
 
 	// well... shit, all things has to have the same type and in case of structure, this could be fun, in case of zero items, well... fuck, special structure for this?
 	if len(input.value) == 0 { // nothing, so not interesting in anything, encopde it as a zero empty structures
@@ -553,8 +732,11 @@ func encodeCompactArray(out *bytes.Buffer, d *DlmsData) (err error) {
 			for _, tt := range input.tags {
 				out.WriteByte(byte(tt))
 			}
+		} else if input.tag == TagArray {
+			out.WriteByte(byte(input.tags[0]))
 		}
 		out.WriteByte(0) // zero bytes, this is very questionable, at least not so used data type, things for some future
+		return nil
 	}
 	for _, t := range input.value {
 		if t.Tag != input.tag {
@@ -573,6 +755,16 @@ func encodeCompactArray(out *bytes.Buffer, d *DlmsData) (err error) {
 					return fmt.Errorf("inner structure differs")
 				}
 			}
+		} else if input.tag == TagArray {
+			tmp, err := getarraytypes(&t)
+			if err != nil {
+				return err
+			}
+			for _, jj := range tmp {
+				if jj != input.tags[0] {
+					return fmt.Errorf("inner array item type differs")
+				}
+			}
 		}
 	}
 
@@ -596,6 +788,8 @@ func encodeCompactArray(out *bytes.Buffer, d *DlmsData) (err error) {
 		for _, tt := range input.tags {
 			out.WriteByte(byte(tt))
 		}
+	} else if input.tag == TagArray {
+		out.WriteByte(byte(input.tags[0]))
 	}
 	// ok, create internal buffer, encode shits, determine size and put that together
 	var internal bytes.Buffer
@@ -606,6 +800,15 @@ func encodeCompactArray(out *bytes.Buffer, d *DlmsData) (err error) {
 				return err
 			}
 		}
+	} else if input.tag == TagArray { // every inner array has its own length
+		for _, dd := range input.value {
+			tmp, _ := getarraytypes(&dd) // already checked above
+			encodelength(&internal, uint(len(tmp)))
+			err = encodeStructureWithoutTags(&internal, &dd)
+			if err != nil {
+				return err
+			}
+		}
 	} else {
 		for _, dd := range input.value {
 			err = encodeDatanoTag(&internal, &dd)
@@ -645,6 +848,17 @@ func getstructuretypes(d *DlmsData) ([]dataTag, error) {
 	if d.Tag != TagStructure {
 		return nil, fmt.Errorf("data are not a structure")
 	}
+	return getitemtypes(d)
+}
+
+func getarraytypes(d *DlmsData) ([]dataTag, error) {
+	if d.Tag != TagArray {
+		return nil, fmt.Errorf("data are not an array")
+	}
+	return getitemtypes(d)
+}
+
+func getitemtypes(d *DlmsData) ([]dataTag, error) {
 	switch t := d.Value.(type) {
 	case []*DlmsData:
 		r := make([]dataTag, len(t))
@@ -659,7 +873,7 @@ func getstructuretypes(d *DlmsData) ([]dataTag, error) {
 		}
 		return r, nil
 	default:
-		return nil, fmt.Errorf("invalid inner structure data")
+		return nil, fmt.Errorf("invalid inner items data")
 	}
 }
 
@@ -677,10 +891,15 @@ func encodeBCD(out *bytes.Buffer, d *DlmsData) error {
 	default:
 		return fmt.Errorf("unsupported data type for BCD: %T", d.Value)
 	}
-	b := byte(((lr/10)%10)<<4) | byte(lr%10)
-	if lr < 0 {
-		b |= 0x80
+	var b byte
+	if lr < 0 { // sign bit and magnitude, the same as decoding
+		b = 0x80
+		lr = -lr
 	}
+	if lr > 79 {
+		return fmt.Errorf("BCD value out of range: %v", d.Value)
+	}
+	b |= byte(lr/10)<<4 | byte(lr%10)
 	out.WriteByte(b)
 	return nil
 }
@@ -713,6 +932,7 @@ func encodeOctetString(out *bytes.Buffer, d *DlmsData) error {
 		encodeobis(out, *t)
 	case time.Time:
 		dt := NewDlmsDateTimeFromTime(t)
+		encodelength(out, 12)
 		encodedatetime(out, dt)
 	default:
 		return fmt.Errorf("unsupported data type for octet string: %T", d.Value)
@@ -845,6 +1065,8 @@ func encodeInteger(out *bytes.Buffer, d *DlmsData, len int) error {
 		}
 	case uint8:
 		lr = uint64(t)
+	case DlmsEnum:
+		lr = uint64(t)
 	case uint16:
 		lr = uint64(t)
 	case uint32:
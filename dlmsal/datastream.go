@@ -45,27 +45,40 @@ type datastreamstate struct {
 }
 
 func newDataStream(src io.Reader, inmem bool, logger *zap.SugaredLogger) (DlmsDataStream, error) {
+	if inmem { // readout everything from src
+		mem := NewChunkedStream()
+		err := mem.CopyFrom(src)
+		if err != nil {
+			return nil, err
+		}
+		return newMemDataStream(mem, logger), nil
+	}
 	ret := datastream{
 		stack:    make([]datastreamstate, 1),
 		inerror:  false,
 		ineof:    false,
 		logger:   logger,
-		inmemory: inmem,
-	}
-	if inmem { // readout everything from src
-		ret.mem = NewChunkedStream()
-		err := ret.mem.CopyFrom(src)
-		if err != nil {
-			return nil, err
-		}
-		ret.src = ret.mem
-	} else {
-		ret.src = src
+		inmemory: false,
+		src:      src,
 	}
 	ret.stack[0] = datastreamstate{items: 1, element: TagError} // fake
 	return &ret, nil
 }
 
+func newMemDataStream(mem ChunkedStream, logger *zap.SugaredLogger) DlmsDataStream {
+	ret := datastream{
+		stack:    make([]datastreamstate, 1),
+		inerror:  false,
+		ineof:    false,
+		logger:   logger,
+		inmemory: true,
+		mem:      mem,
+		src:      mem,
+	}
+	ret.stack[0] = datastreamstate{items: 1, element: TagError} // fake
+	return &ret
+}
+
 func (d *datastream) Rewind() error {
 	if d.inmemory {
 		d.mem.Rewind()
@@ -0,0 +1,218 @@
+package dlmsal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+type AccessRequestType byte
+
+const (
+	AccessRequestGet    AccessRequestType = 1
+	AccessRequestSet    AccessRequestType = 2
+	AccessRequestAction AccessRequestType = 3
+	// with selection variants (4, 5) are chosen automatically by HasAccess of the item
+	accessRequestGetWithSelection AccessRequestType = 4
+	accessRequestSetWithSelection AccessRequestType = 5
+)
+
+type AccessRequestItem struct {
+	Type AccessRequestType
+	Item DlmsLNRequestItem // SetData is used for set and action
+}
+
+type AccessResponseItem struct {
+	Result DlmsResultTag
+	Data   DlmsData // null for set and action without returned data
+}
+
+func (d *dlmsal) encodeaccessrequest(requests []AccessRequestItem) error {
+	local := &d.pdu
+	local.Reset()
+	local.WriteByte(byte(TagAccessRequest))
+	d.longinvokeid = (d.longinvokeid + 1) & 0xffffff
+	d.takeflags()
+	var lid [4]byte
+	binary.BigEndian.PutUint32(lid[:], uint32(d.invokeflags)<<24|d.longinvokeid) // priority and service class are the top bits as well
+	local.Write(lid[:])
+	local.WriteByte(0) // empty date-time
+
+	encodelength(local, uint(len(requests)))
+	for i := range requests {
+		r := &requests[i]
+		item := &r.Item
+		switch r.Type {
+		case AccessRequestGet:
+			if item.HasAccess {
+				local.WriteByte(byte(accessRequestGetWithSelection))
+			} else {
+				local.WriteByte(byte(AccessRequestGet))
+			}
+		case AccessRequestSet:
+			if item.HasAccess {
+				local.WriteByte(byte(accessRequestSetWithSelection))
+			} else {
+				local.WriteByte(byte(AccessRequestSet))
+			}
+		case AccessRequestAction:
+			if item.HasAccess {
+				return fmt.Errorf("action item cant have access")
+			}
+			local.WriteByte(byte(AccessRequestAction))
+		default:
+			return fmt.Errorf("unsupported access request type %v", r.Type)
+		}
+		encodelncosemattr(local, item)
+		if item.HasAccess {
+			local.WriteByte(item.AccessDescriptor)
+			err := encodeData(local, item.AccessData)
+			if err != nil {
+				return fmt.Errorf("unable to encode access data: %w", err)
+			}
+		}
+	}
+
+	encodelength(local, uint(len(requests)))
+	for i := range requests {
+		r := &requests[i]
+		if r.Type == AccessRequestGet || r.Item.SetData == nil {
+			if r.Type == AccessRequestSet {
+				return fmt.Errorf("no data to set for item %d", i)
+			}
+			local.WriteByte(byte(TagNull))
+			continue
+		}
+		err := encodeData(local, r.Item.SetData)
+		if err != nil {
+			return fmt.Errorf("unable to encode data: %w", err)
+		}
+	}
+	return nil
+}
+
+// single access request with mixed get/set/action items, no block transfer and no ciphering (that would need general ciphering)
+func (d *dlmsal) Access(requests []AccessRequestItem) ([]AccessResponseItem, error) {
+	if !d.isopen {
+		return nil, base.ErrNotOpened
+	}
+	if d.authpending {
+		return nil, ErrAuthenticationPending
+	}
+	if len(requests) == 0 {
+		return nil, base.ErrNothingToRead
+	}
+	err := d.encodeaccessrequest(requests)
+	if err != nil {
+		return nil, err
+	}
+
+	tag, str, err := d.sendpdu()
+	if err != nil {
+		return nil, err
+	}
+	switch tag {
+	case TagAccessResponse:
+	case TagExceptionResponse:
+		ex, err := decodeException(str, &d.tmpbuffer)
+		if err != nil {
+			return nil, err
+		}
+		ret := make([]AccessResponseItem, len(requests))
+		for i := range ret {
+			ret[i] = AccessResponseItem{Result: ex.Value.(*DlmsError).Result, Data: ex}
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("unexpected tag: %02x", tag)
+	}
+	return d.decodeaccessresponse(str, len(requests))
+}
+
+func (d *dlmsal) decodeaccessresponse(src io.Reader, count int) ([]AccessResponseItem, error) {
+	_, err := io.ReadFull(src, d.tmpbuffer[:5])
+	if err != nil {
+		return nil, err
+	}
+	d.lastinvoke = d.tmpbuffer[0] // only priority and service class here
+	if binary.BigEndian.Uint32(d.tmpbuffer[:4])&0xffffff != d.longinvokeid {
+		return nil, fmt.Errorf("unexpected invoke id")
+	}
+	if d.tmpbuffer[4] != 0 { // date-time, just skip it
+		_, err = io.CopyN(io.Discard, src, int64(d.tmpbuffer[4]))
+		if err != nil {
+			return nil, err
+		}
+	}
+	_, err = io.ReadFull(src, d.tmpbuffer[:1])
+	if err != nil {
+		return nil, err
+	}
+	if d.tmpbuffer[0] != 0 { // request specification echoed back, skip it
+		err = d.skipaccessspec(src)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l, _, err := decodelength(src, &d.tmpbuffer)
+	if err != nil {
+		return nil, err
+	}
+	if int(l) != count {
+		return nil, fmt.Errorf("unexpected number of data items: %d, expected %d", l, count)
+	}
+	ret := make([]AccessResponseItem, count)
+	for i := range ret {
+		ret[i].Data, _, err = decodeDataTag(src, &d.tmpbuffer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l, _, err = decodelength(src, &d.tmpbuffer)
+	if err != nil {
+		return nil, err
+	}
+	if int(l) != count {
+		return nil, fmt.Errorf("unexpected number of results: %d, expected %d", l, count)
+	}
+	for i := range ret {
+		_, err = io.ReadFull(src, d.tmpbuffer[:2]) // choice and result
+		if err != nil {
+			return nil, err
+		}
+		ret[i].Result = DlmsResultTag(d.tmpbuffer[1])
+		if ret[i].Result != TagResultSuccess && ret[i].Data.Tag == TagNull {
+			ret[i].Data = NewDlmsDataError(ret[i].Result)
+		}
+	}
+	return ret, nil
+}
+
+func (d *dlmsal) skipaccessspec(src io.Reader) error {
+	l, _, err := decodelength(src, &d.tmpbuffer)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < int(l); i++ {
+		_, err = io.ReadFull(src, d.tmpbuffer[:10]) // choice and descriptor
+		if err != nil {
+			return err
+		}
+		switch AccessRequestType(d.tmpbuffer[0]) {
+		case accessRequestGetWithSelection, accessRequestSetWithSelection:
+			_, err = io.ReadFull(src, d.tmpbuffer[:1])
+			if err != nil {
+				return err
+			}
+			_, _, err = decodeDataTag(src, &d.tmpbuffer)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/cybroslabs/libdlms-go/base"
 	"github.com/cybroslabs/libdlms-go/gcm"
@@ -67,13 +69,43 @@ type DlmsClient interface {
 	Open() error
 	SetLogger(logger *zap.SugaredLogger)
 	Get(items []DlmsLNRequestItem) ([]DlmsData, error)
+	GetExpect(item DlmsLNRequestItem, expectedTag dataTag) (DlmsData, error)
+	GetDedup(items []DlmsLNRequestItem) ([]DlmsData, error)
+	GetTyped(item DlmsLNRequestItem, schema Schema) (map[string]Value, error)
+	ReadRegisters(obisList []DlmsObis) ([]ScaledValue, error)
+	ReadDemandRegister(obis DlmsObis) (DemandRegister, error)
+	ResetDemandRegister(obis DlmsObis) error
+	NextDemandPeriod(obis DlmsObis) error
+	RemoteDisconnect(obis DlmsObis) error
+	RemoteReconnect(obis DlmsObis) error
+	ReadDisconnectState(obis DlmsObis) (ControlState, error)
+	ReadSecuritySetup(obis DlmsObis) (SecuritySetup, error)
+	ConfigurePush(obis DlmsObis, cfg PushConfig) error
+	ReadPushSetup(obis DlmsObis) (PushConfig, error)
+	ClockDrift(ref time.Time) (time.Duration, error)
+	ProfileInfo(obis DlmsObis) (ProfileInfo, error)
+	ReadObjectList() ([]ObjectListEntry, error)
+	DumpObjects(filter func(ObjectListEntry) bool) ([]DumpedObject, error)
 	GetStream(item DlmsLNRequestItem, inmem bool) (DlmsDataStream, error)
+	ResumeGet() (DlmsDataStream, error)
 	Read(items []DlmsSNRequestItem) ([]DlmsData, error)
 	ReadStream(item DlmsSNRequestItem, inmem bool) (DlmsDataStream, error) // only for big single item queries
 	Write(items []DlmsSNRequestItem) ([]DlmsResultTag, error)
+	ResolveSN(classId uint16, obis DlmsObis, attr int8) (int16, error)
 	Action(item DlmsLNRequestItem) (*DlmsData, error)
 	Set(items []DlmsLNRequestItem) ([]DlmsResultTag, error)
+	Access(requests []AccessRequestItem) ([]AccessResponseItem, error)
+	GetArrayElement(item DlmsLNRequestItem, index uint16) (DlmsData, error)
+	SetArrayElement(item DlmsLNRequestItem, index uint16, value DlmsData) (DlmsResultTag, error)
 	LNAuthentication(checkresp bool) error
+	SetNextRequestFlags(highPriority bool, confirmed bool)
+	LastResponseSecurity() (sc byte, ok bool)
+	LastAareTags() []AareTag
+	AuthenticationPending() bool
+	StartTranscript(w io.Writer)
+	StopTranscript()
+	LastResponseInfo() (tag CosemTag, invokeId byte)
+	ResetSession() error
 }
 
 type tmpbuffer [128]byte
@@ -87,10 +119,31 @@ type dlmsal struct {
 	maxPduSendSize int
 
 	// things for communications/data parsing
-	invokeid    byte
-	tmpbuffer   tmpbuffer
-	pdu         bytes.Buffer // reused for sending requests
-	cryptbuffer []byte       // reusable crypt buffer
+	invokeid     byte
+	invokeflags  byte // priority and service class of the current request
+	nextflags    *byte
+	answered     bool   // response to the current invoke id came
+	unanswered   uint16 // bitmask of invoke ids of confirmed requests without response, late answers can still come
+	longinvokeid uint32 // for access service
+	tmpbuffer    tmpbuffer
+	pdu          bytes.Buffer // reused for sending requests
+	cryptbuffer  []byte       // reusable crypt buffer
+
+	lastsc       byte // security control of the last received response
+	lastciphered bool
+	lasttag      CosemTag
+	lastinvoke   byte
+
+	resume *getresume
+
+	snobjects map[snobjectkey]int16 // cached sn object list
+	scalers   map[DlmsObis]scalerunit
+
+	aaretags []AareTag // all elements of the last aare
+
+	authpending bool // aare said authentication required and hls wasnt done yet
+
+	transcript *transcript
 }
 
 type DlmsSettings struct {
@@ -100,11 +153,44 @@ type DlmsSettings struct {
 	HighPriority      bool
 	ConfirmedRequests bool
 	EmptyRLRQ         bool
+	MaxBlocks         int // maximum blocks of a single transfer, zero means default
 	Security          DlmsSecurity
 	StoC              []byte
 	CtoS              []byte
 	SourceDiagnostic  SourceDiagnostic
 
+	// encoded oid of application context without the last context id byte, nil means standard 2.16.756.5.8.1
+	ApplicationContextOID []byte
+
+	// some meters send get-response-normal data without the leading result byte, when set, unexpected data tag there is taken as data start
+	TolerateMissingResultByte bool
+
+	// some meters answer set with list by a single result (set-response-normal or last-datablock) instead of a result per item,
+	// when set, that result is applied to all items, otherwise such response is an error
+	TolerateSingleSetResult bool
+
+	// some legacy meters echo class, obis and attribute of the request in get-response-normal before the result,
+	// when set, such echo is skipped (only if it matches the request), strict parsing otherwise
+	TolerateEchoedDescriptor bool
+
+	// association result, requests and block progress are logged with key value fields (Infow/Debugw) instead of formatted text
+	StructuredLogging bool
+
+	// general block transfer receive window announced in acks, meter can stream that many blocks before waiting for ack,
+	// zero means 1 (every block acked), maximum is 63
+	GbtWindowSize uint8
+
+	// forced choice of calling-authentication-value (AuthValueCharString or AuthValueBitString), zero means selected by mechanism
+	AuthenticationValueTag byte
+
+	// called with every frame counter value used for ciphering (requests and hls reply), persist it so a restarted
+	// client never reuses one, called synchronously so keep it short
+	OnFrameCounterUsed func(fc uint32)
+
+	// body of every ciphered request (including gmac tag) is zeroed in hex logs of all layers below, their framing (hdlc control
+	// frames, headers) stays visible, base.SetRedactSecrets has to be on
+	RedactCipheredLog bool
+
 	// private part
 	invokebyte         byte
 	authentication     Authentication
@@ -119,6 +205,18 @@ type DlmsSettings struct {
 	akcopy             []byte
 }
 
+// current outgoing framecounter, persist it after the session so the next one can continue
+func (d *DlmsSettings) FrameCounter() uint32 {
+	return d.framecounter
+}
+
+func (d *DlmsSettings) useframecounter() {
+	if d.OnFrameCounterUsed != nil {
+		d.OnFrameCounterUsed(d.framecounter)
+	}
+	d.framecounter++
+}
+
 func (d *DlmsSettings) SetDedicatedKey(key []byte) (err error) {
 	if key == nil {
 		d.dedgcm = nil
@@ -129,6 +227,23 @@ func (d *DlmsSettings) SetDedicatedKey(key []byte) (err error) {
 	return
 }
 
+// rotates AK of global and dedicated ciphering, dont call it during a request
+func (d *DlmsSettings) SetAuthenticationKey(ak []byte) error {
+	if d.gcm == nil {
+		return fmt.Errorf("no ciphering set")
+	}
+	if err := d.gcm.SetAuthenticationKey(ak); err != nil {
+		return err
+	}
+	if d.dedgcm != nil {
+		if err := d.dedgcm.SetAuthenticationKey(ak); err != nil {
+			return err
+		}
+	}
+	d.akcopy = newcopy(ak)
+	return nil
+}
+
 func NewSettingsWithLowAuthenticationSN(password string) (*DlmsSettings, error) {
 	if len(password) == 0 {
 		return nil, fmt.Errorf("password is empty")
@@ -171,6 +286,15 @@ func NewSettingsNoAuthenticationLN() (*DlmsSettings, error) {
 }
 
 func NewSettingsWithGmacLN(systemtitle []byte, ek []byte, ak []byte, ctoshash []byte, fc uint32) (*DlmsSettings, error) {
+	return newsettingsgmac(systemtitle, ek, ak, ctoshash, fc, SecurityEncryption|SecurityAuthentication)
+}
+
+// apdus are sent in plaintext but protected by gmac tag (security control 0x10)
+func NewSettingsWithGmacAuthenticationOnlyLN(systemtitle []byte, ek []byte, ak []byte, ctoshash []byte, fc uint32) (*DlmsSettings, error) {
+	return newsettingsgmac(systemtitle, ek, ak, ctoshash, fc, SecurityAuthentication)
+}
+
+func newsettingsgmac(systemtitle []byte, ek []byte, ak []byte, ctoshash []byte, fc uint32, security DlmsSecurity) (*DlmsSettings, error) {
 	if len(systemtitle) != 8 {
 		return nil, fmt.Errorf("systemtitle has to be 8 bytes long")
 	}
@@ -195,7 +319,7 @@ func NewSettingsWithGmacLN(systemtitle []byte, ek []byte, ak []byte, ctoshash []
 		akcopy:       newcopy(ak), // this is sad...
 		password:     newcopy(ctoshash),
 		framecounter: fc,
-		Security:     SecurityEncryption | SecurityAuthentication,
+		Security:     security,
 	}
 	ret.CtoS = ret.password // just reference
 	return &ret, nil
@@ -218,12 +342,112 @@ func New(transport base.Stream, settings *DlmsSettings) DlmsClient {
 	}
 }
 
+// overrides HighPriority and ConfirmedRequests settings only for the next get/set/action request
+func (d *dlmsal) SetNextRequestFlags(highPriority bool, confirmed bool) {
+	var f byte
+	if highPriority {
+		f |= 0x80
+	}
+	if confirmed {
+		f |= 0x40
+	}
+	d.nextflags = &f
+}
+
+// new invoke id for a new request, consumes one time flags override, ids of unanswered requests are skipped
+func (d *dlmsal) nextinvoke() byte {
+	if !d.answered && d.invokeflags&0x40 != 0 {
+		d.unanswered |= 1 << d.invokeid
+	}
+	if d.unanswered == 0xffff { // everything is stale, nothing to choose from, so just forget it
+		d.unanswered = 0
+	}
+	for {
+		d.invokeid = (d.invokeid + 1) & 0x0f
+		if d.unanswered&(1<<d.invokeid) == 0 {
+			break
+		}
+	}
+	d.answered = false
+	d.takeflags()
+	return d.invokeid | d.invokeflags
+}
+
+// priority and service class of the new request, pending SetNextRequestFlags override is consumed
+func (d *dlmsal) takeflags() {
+	d.invokeflags = d.settings.invokebyte
+	if d.nextflags != nil {
+		d.invokeflags = *d.nextflags
+		d.nextflags = nil
+	}
+}
+
+const defaultMaxBlocks = 100000
+
+func (d *dlmsal) checkblocks(op string, n uint32) error {
+	d.debugw("block", "op", op, "block", n)
+	m := d.settings.MaxBlocks
+	if m <= 0 {
+		m = defaultMaxBlocks
+	}
+	if uint64(n) > uint64(m) {
+		return ErrTooManyBlocks
+	}
+	return nil
+}
+
+// the same invoke byte for continuation of the current request (blocks)
+func (d *dlmsal) currentinvoke() byte {
+	return d.invokeid | d.invokeflags
+}
+
 func (w *dlmsal) logf(format string, v ...any) {
 	if w.logger != nil {
 		w.logger.Infof(format, v...)
 	}
 }
 
+// structured log with key value pairs, formatted into text in case StructuredLogging is off
+func (d *dlmsal) logw(msg string, kv ...any) {
+	if d.logger == nil {
+		return
+	}
+	if d.settings.StructuredLogging {
+		d.logger.Infow(msg, kv...)
+	} else {
+		d.logger.Info(formatkv(msg, kv))
+	}
+}
+
+func (d *dlmsal) debugw(msg string, kv ...any) {
+	if d.logger == nil {
+		return
+	}
+	if d.settings.StructuredLogging {
+		d.logger.Debugw(msg, kv...)
+	} else {
+		d.logger.Debug(formatkv(msg, kv))
+	}
+}
+
+func formatkv(msg string, kv []any) string {
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for i := 0; i+1 < len(kv); i += 2 {
+		fmt.Fprintf(&sb, " %v=%v", kv[i], kv[i+1])
+	}
+	return sb.String()
+}
+
+func (d *dlmsal) logitems(op string, items []DlmsLNRequestItem) {
+	if d.logger == nil {
+		return
+	}
+	for i := range items {
+		d.logw(op, "obis", items[i].Obis.String(), "class", items[i].ClassId, "attr", items[i].Attribute)
+	}
+}
+
 func (d *dlmsal) Close() error {
 	if !d.isopen {
 		return nil
@@ -233,11 +457,17 @@ func (d *dlmsal) Close() error {
 	if err != nil {
 		return err
 	}
+	if d.transcript != nil {
+		d.transcript.tx(rl, nil)
+	}
 	err = d.transport.Write(rl)
 	if err != nil {
 		return err
 	}
-	_, err = d.smallreadout() // yes, this is bullshit
+	rlre, err := d.smallreadout() // yes, this is bullshit
+	if d.transcript != nil && err == nil {
+		d.transcript.rx(rlre)
+	}
 	d.isopen = false
 	if err != nil { // just ignore data itself as simulator returns some weird shit (based on e650 maybe)
 		return err
@@ -247,10 +477,23 @@ func (d *dlmsal) Close() error {
 }
 
 func (d *dlmsal) Disconnect() error {
+	if d.transcript != nil {
+		d.transcript.flush()
+	}
 	d.isopen = false
 	return d.transport.Disconnect()
 }
 
+// true after Open in case meter requires hls and LNAuthentication didnt succeed yet, get and set fail with ErrAuthenticationPending till then
+func (d *dlmsal) AuthenticationPending() bool {
+	return d.isopen && d.authpending
+}
+
+// elements of the last received aare including unknown ones, kept also when association failed, data slices are shared so dont modify them
+func (d *dlmsal) LastAareTags() []AareTag {
+	return d.aaretags
+}
+
 func (d *dlmsal) smallreadout() ([]byte, error) {
 	// safely use already existing buffer, it could fail if aare is bigger than it, but it can be solved later
 	total := 0
@@ -283,11 +526,21 @@ func (d *dlmsal) Open() error { // login and shits
 	if err := d.transport.Open(); err != nil {
 		return err
 	}
+	d.scalers = nil
+	d.aaretags = nil
+	d.unanswered = 0
+	d.answered = true // nothing is outstanding in a new association, flags stay for ResumeGet
 
-	b, err := d.encodeaarq()
+	b, secret, err := d.encodeaarq()
 	if err != nil {
 		return err
 	}
+	if d.transcript != nil {
+		d.transcript.tx(b, nil)
+	}
+	if secret.Length > 0 { // password goes in plain form in aarq, so hide it in hex logs
+		base.RedactNextWrite(d.transport, []base.Redaction{secret})
+	}
 	err = d.transport.Write(b)
 	if err != nil {
 		return err
@@ -296,6 +549,9 @@ func (d *dlmsal) Open() error { // login and shits
 	if err != nil {
 		return fmt.Errorf("unable to receive snrm: %w", err)
 	}
+	if d.transcript != nil {
+		d.transcript.rx(aare)
+	}
 	// parse aare
 	tag, _, data, err := decodetag(aare, &d.tmpbuffer)
 	if err != nil {
@@ -308,10 +564,11 @@ func (d *dlmsal) Open() error { // login and shits
 	if err != nil {
 		return fmt.Errorf("unable to parse aare: %w", err)
 	}
+	d.aaretags = tags
 	for _, dt := range tags {
-		switch dt.tag {
+		switch dt.Tag {
 		case BERTypeContext | BERTypeConstructed | PduTypeApplicationContextName: // 0xa1
-			d.aareres.ApplicationContextName, err = parseApplicationContextName(&dt)
+			d.aareres.ApplicationContextName, err = parseApplicationContextName(&dt, d.settings)
 		case BERTypeContext | BERTypeConstructed | PduTypeCalledAPTitle: // 0xa2
 			d.aareres.AssociationResult, err = parseAssociationResult(&dt)
 		case BERTypeContext | BERTypeConstructed | PduTypeCalledAEQualifier: // 0xa3
@@ -323,7 +580,7 @@ func (d *dlmsal) Open() error { // login and shits
 		case BERTypeContext | BERTypeConstructed | PduTypeUserInformation: // 0xbe
 			d.aareres.initiateResponse, d.aareres.confirmedServiceError, err = d.parseUserInformation(&dt)
 		default:
-			d.logf("Unknown tag: %02x", dt.tag)
+			d.logf("Unknown tag: %02x", dt.Tag)
 		}
 
 		if err != nil {
@@ -331,8 +588,10 @@ func (d *dlmsal) Open() error { // login and shits
 		}
 	}
 
+	d.logw("association", "result", d.aareres.AssociationResult, "diagnostic", d.aareres.SourceDiagnostic, "context", d.aareres.ApplicationContextName)
+
 	if d.aareres.confirmedServiceError != nil {
-		return fmt.Errorf("confirmed service error: %v", d.aareres.confirmedServiceError.ConfirmedServiceError)
+		return d.aareres.confirmedServiceError
 	}
 	if d.aareres.ApplicationContextName != d.settings.applicationContext {
 		return fmt.Errorf("application contextes differ: %v != %v", d.aareres.ApplicationContextName, d.settings.applicationContext)
@@ -356,6 +615,7 @@ func (d *dlmsal) Open() error { // login and shits
 
 	d.settings.VAAddress = d.aareres.initiateResponse.VAAddress // returning from interface, a bit hacky yes
 
+	d.authpending = d.aareres.SourceDiagnostic == SourceDiagnosticAuthenticationRequired
 	d.isopen = true
 	return nil
 }
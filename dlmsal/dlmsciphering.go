@@ -35,7 +35,7 @@ func (d *dlmsal) encryptpacket(tag byte, apdu []byte, ded bool) []byte {
 	} else {
 		_, _ = s.gcm.Encrypt(d.cryptbuffer[off:], byte(s.Security), s.framecounter, s.systemtitle, apdu)
 	}
-	s.framecounter++
+	s.useframecounter()
 	return d.cryptbuffer[:off+wl]
 }
 
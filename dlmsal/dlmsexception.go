@@ -2,20 +2,66 @@ package dlmsal
 
 import (
 	"errors"
+	"fmt"
 	"io"
 )
 
+type ExceptionStateError byte
+
+const (
+	ExceptionStateServiceNotAllowed ExceptionStateError = 1
+	ExceptionStateServiceUnknown    ExceptionStateError = 2
+)
+
+type ExceptionServiceError byte
+
+const (
+	ExceptionServiceOperationNotPossible   ExceptionServiceError = 1
+	ExceptionServiceNotSupported           ExceptionServiceError = 2
+	ExceptionServiceOtherReason            ExceptionServiceError = 3
+	ExceptionServicePduTooLong             ExceptionServiceError = 4
+	ExceptionServiceDecipheringError       ExceptionServiceError = 5
+	ExceptionServiceInvocationCounterError ExceptionServiceError = 6
+)
+
+// decoded exception-response, zero values mean the field wasnt present (short form sent by some meters)
+type ExceptionError struct {
+	StateError   ExceptionStateError
+	ServiceError ExceptionServiceError
+	// expected invocation counter, only with ExceptionServiceInvocationCounterError
+	InvocationCounter    uint32
+	HasInvocationCounter bool
+}
+
+func (e *ExceptionError) Error() string {
+	if e.HasInvocationCounter {
+		return fmt.Sprintf("exception response, state error: %d, service error: %d, invocation counter: %d", e.StateError, e.ServiceError, e.InvocationCounter)
+	}
+	return fmt.Sprintf("exception response, state error: %d, service error: %d", e.StateError, e.ServiceError)
+}
+
 func decodeException(src io.Reader, tmp *tmpbuffer) (e DlmsData, err error) {
+	ex := ExceptionError{}
 	var n int
 	n, err = io.ReadFull(src, tmp[:2])
 	switch n {
 	case 0:
-		e = NewDlmsDataError(TagResultOtherReason)
-	case 1, 2:
-		e = NewDlmsDataError(TagResultOtherReason) // not decoding state-error or service-error
+	case 1:
+		ex.StateError = ExceptionStateError(tmp[0])
+	case 2:
+		ex.StateError = ExceptionStateError(tmp[0])
+		ex.ServiceError = ExceptionServiceError(tmp[1])
+		if err == nil && ex.ServiceError == ExceptionServiceInvocationCounterError {
+			n, err = io.ReadFull(src, tmp[:4])
+			if n == 4 {
+				ex.InvocationCounter = uint32(tmp[0])<<24 | uint32(tmp[1])<<16 | uint32(tmp[2])<<8 | uint32(tmp[3])
+				ex.HasInvocationCounter = true
+			}
+		}
 	default:
 		panic("programatic error, unexpected read bytes count")
 	}
+	e = DlmsData{Tag: TagError, Value: &DlmsError{Result: TagResultOtherReason, Exception: &ex}}
 	if err != nil {
 		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 			err = nil
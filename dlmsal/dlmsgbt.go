@@ -0,0 +1,87 @@
+package dlmsal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+const (
+	gbtLastBlock     = 0x80
+	gbtStreaming     = 0x40
+	gbtWindowMask    = 0x3f
+	gbtMaxWindowSize = gbtWindowMask
+)
+
+// receives general block transfer response (tag byte is already read) and returns reassembled apdu, only response direction is supported,
+// meter has to be allowed to use it by ConformanceBlockGeneralBlockTransfer, lost blocks are not recovered, transfer just fails
+func (d *dlmsal) recvgbt() (io.Reader, error) {
+	var apdu bytes.Buffer
+	expected := uint16(1)
+	sent := uint16(0)   // our own block numbers, request itself wasnt gbt
+	unacked := uint8(0) // blocks received since the last ack
+	window := d.gbtwindow()
+	for {
+		_, err := io.ReadFull(d.transport, d.tmpbuffer[:5])
+		if err != nil {
+			return nil, fmt.Errorf("unable to read general block transfer header: %w", err)
+		}
+		bc := d.tmpbuffer[0]
+		bn := binary.BigEndian.Uint16(d.tmpbuffer[1:])
+		if bn != expected {
+			return nil, fmt.Errorf("unexpected general block transfer block number %d, expected %d", bn, expected)
+		}
+		if err = d.checkblocks("gbt", uint32(bn)); err != nil {
+			return nil, err
+		}
+		l, _, err := decodelength(d.transport, &d.tmpbuffer)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.CopyN(&apdu, d.transport, int64(l))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read general block transfer data: %w", err)
+		}
+		if bc&gbtLastBlock != 0 {
+			return &apdu, nil
+		}
+		expected++
+		unacked++
+
+		// streaming bit cleared means end of the meter window and it waits for ack, otherwise ack once our window is full
+		// so the meter can continue streaming without stall
+		if bc&gbtStreaming == 0 || unacked >= window {
+			sent++
+			if err = d.sendgbtack(sent, bn, window); err != nil {
+				return nil, err
+			}
+			unacked = 0
+		}
+		tag, err := d.readtag(d.transport)
+		if err != nil {
+			return nil, err
+		}
+		if tag != TagGeneralBlockTransfer {
+			return nil, fmt.Errorf("unexpected tag %v during general block transfer", tag)
+		}
+	}
+}
+
+func (d *dlmsal) gbtwindow() uint8 {
+	w := d.settings.GbtWindowSize
+	if w == 0 {
+		return 1
+	}
+	return min(w, gbtMaxWindowSize)
+}
+
+func (d *dlmsal) sendgbtack(bn uint16, ack uint16, window uint8) error {
+	var b [7]byte
+	b[0] = byte(TagGeneralBlockTransfer)
+	b[1] = window & gbtWindowMask
+	binary.BigEndian.PutUint16(b[2:], bn)
+	binary.BigEndian.PutUint16(b[4:], ack)
+	b[6] = 0 // empty block data
+	return d.transport.Write(b[:])
+}
@@ -18,6 +18,7 @@ type dlmsalaction struct { // this will implement io.Reader for LN Action operat
 	// 100 game over all read
 	blockexp  uint32
 	lastblock bool
+	blocks    uint32 // received blocks in this transfer
 	remaining uint
 	transport io.Reader
 }
@@ -45,13 +46,17 @@ func (ln *dlmsalaction) action(item DlmsLNRequestItem) (data *DlmsData, err erro
 	local.Reset()
 	local.WriteByte(byte(TagActionRequest))
 	local.WriteByte(byte(TagActionRequestNormal))
-	master.invokeid = (master.invokeid + 1) & 7
-	local.WriteByte(master.invokeid | master.settings.invokebyte)
+	local.WriteByte(master.nextinvoke())
 	err = encodelnactionitem(local, &item)
 	if err != nil {
 		return
 	}
 
+	if master.unconfirmed() { // nothing comes back
+		ln.state = 100
+		return nil, master.sendunconfirmed()
+	}
+
 	// send itself, that could be fun, do that in one step for now
 	tag, str, err := master.sendpdu()
 	if err != nil {
@@ -81,7 +86,7 @@ func (ln *dlmsalaction) actiondata(tag CosemTag) (data *DlmsData, err error) {
 			return data, err
 		}
 
-		if master.tmpbuffer[1]&7 != master.invokeid {
+		if !master.checkinvoke(master.tmpbuffer[1]) {
 			return data, fmt.Errorf("unexpected invoke id")
 		}
 
@@ -150,6 +155,7 @@ func (ln *dlmsalaction) Read(p []byte) (n int, err error) { // this will go to d
 		ln.lastblock = master.tmpbuffer[0] != 0
 		blockno := (uint32(master.tmpbuffer[1]) << 24) | (uint32(master.tmpbuffer[2]) << 16) | (uint32(master.tmpbuffer[3]) << 8) | uint32(master.tmpbuffer[4])
 		ln.blockexp = blockno
+		ln.blocks = 1
 		ln.remaining, _, err = decodelength(ln.transport, &master.tmpbuffer) // refactor usage of these tmp buffers...
 		if err != nil {
 			return 0, err
@@ -169,12 +175,16 @@ func (ln *dlmsalaction) Read(p []byte) (n int, err error) { // this will go to d
 			if ln.lastblock {
 				return 0, io.EOF // or some common error?
 			}
+			if err = master.checkblocks("action", ln.blocks+1); err != nil {
+				return 0, err
+			}
+			ln.blocks++
 			// ask for the next block
 			local := &master.pdu
 			local.Reset()
 			local.WriteByte(byte(TagActionRequest))
 			local.WriteByte(byte(TagActionRequestNextPBlock))
-			local.WriteByte(master.invokeid | master.settings.invokebyte)
+			local.WriteByte(master.currentinvoke())
 			local.WriteByte(byte(ln.blockexp >> 24))
 			local.WriteByte(byte(ln.blockexp >> 16))
 			local.WriteByte(byte(ln.blockexp >> 8))
@@ -192,7 +202,7 @@ func (ln *dlmsalaction) Read(p []byte) (n int, err error) { // this will go to d
 			if err != nil {
 				return 0, err
 			}
-			if master.tmpbuffer[0] != byte(TagActionResponseWithPBlock) || master.tmpbuffer[1]&7 != master.invokeid {
+			if master.tmpbuffer[0] != byte(TagActionResponseWithPBlock) || !master.checkinvoke(master.tmpbuffer[1]) {
 				return 0, fmt.Errorf("unexpected response tag: %02x", master.tmpbuffer[0])
 			}
 			// set last, check block number and set remaining
@@ -226,6 +236,7 @@ func (d *dlmsal) Action(item DlmsLNRequestItem) (data *DlmsData, err error) { //
 		return nil, base.ErrNotOpened
 	}
 
+	d.logitems("action", []DlmsLNRequestItem{item})
 	ln := &dlmsalaction{master: d, state: 0, blockexp: 0}
 	return ln.action(item)
 }
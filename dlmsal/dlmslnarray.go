@@ -0,0 +1,54 @@
+package dlmsal
+
+import (
+	"fmt"
+)
+
+func arrayelementitem(item DlmsLNRequestItem, index uint16) (DlmsLNRequestItem, error) {
+	if index == 0 {
+		return item, fmt.Errorf("array index starts from 1")
+	}
+	if item.HasAccess {
+		return item, fmt.Errorf("item already has selective access")
+	}
+	ad := EncodeEntryAccess(uint32(index), uint32(index), 1, 0)
+	item.HasAccess = true
+	item.AccessDescriptor = 2
+	item.AccessData = &ad
+	return item, nil
+}
+
+// reads single array element using entry selective access, index is 1 based, meter returns it as an array with single item
+func (d *dlmsal) GetArrayElement(item DlmsLNRequestItem, index uint16) (DlmsData, error) {
+	item, err := arrayelementitem(item, index)
+	if err != nil {
+		return DlmsData{}, err
+	}
+	data, err := d.Get([]DlmsLNRequestItem{item})
+	if err != nil {
+		return DlmsData{}, err
+	}
+	ret := data[0]
+	if ret.Tag == TagArray {
+		a, ok := ret.Value.([]DlmsData)
+		if !ok || len(a) != 1 {
+			return ret, fmt.Errorf("expected array with single element")
+		}
+		return a[0], nil
+	}
+	return ret, nil
+}
+
+// writes single array element, value is the element itself, not wrapped in array
+func (d *dlmsal) SetArrayElement(item DlmsLNRequestItem, index uint16, value DlmsData) (DlmsResultTag, error) {
+	item, err := arrayelementitem(item, index)
+	if err != nil {
+		return TagResultOtherReason, err
+	}
+	item.SetData = &value
+	ret, err := d.Set([]DlmsLNRequestItem{item})
+	if err != nil {
+		return TagResultOtherReason, err
+	}
+	return ret[0], nil
+}
@@ -28,13 +28,10 @@ func (d *dlmsal) LNAuthentication(checkresp bool) error {
 		return fmt.Errorf("no gcm set for ciphering")
 	}
 	// create ctos hash
-	e, err := s.gcm.Encrypt(d.cryptbuffer, byte(SecurityAuthentication), s.framecounter, s.systemtitle, s.StoC)
+	e, err := s.gcm.AuthTag(byte(SecurityAuthentication), s.framecounter, s.systemtitle, s.StoC)
 	if err != nil {
 		return err
 	}
-	if len(e) < gcm.GCM_TAG_LENGTH {
-		return fmt.Errorf("encrypted data too short")
-	}
 
 	hashresp := make([]byte, 5+gcm.GCM_TAG_LENGTH)
 	hashresp[0] = byte(SecurityAuthentication)
@@ -42,7 +39,7 @@ func (d *dlmsal) LNAuthentication(checkresp bool) error {
 	hashresp[2] = byte(s.framecounter >> 16)
 	hashresp[3] = byte(s.framecounter >> 8)
 	hashresp[4] = byte(s.framecounter)
-	copy(hashresp[5:], e[len(e)-gcm.GCM_TAG_LENGTH:])
+	copy(hashresp[5:], e)
 
 	data := DlmsData{Tag: TagOctetString, Value: hashresp}
 	req := DlmsLNRequestItem{
@@ -52,7 +49,7 @@ func (d *dlmsal) LNAuthentication(checkresp bool) error {
 		HasAccess: false,
 		SetData:   &data}
 
-	s.framecounter++
+	s.useframecounter()
 	adata, err := d.Action(req)
 	if err != nil {
 		return err
@@ -61,6 +58,7 @@ func (d *dlmsal) LNAuthentication(checkresp bool) error {
 		return fmt.Errorf("no data received from authentication action")
 	}
 	if !checkresp { // so optimistic
+		d.authpending = false
 		return nil
 	}
 
@@ -73,15 +71,13 @@ func (d *dlmsal) LNAuthentication(checkresp bool) error {
 	if len(aresp) != 5+gcm.GCM_TAG_LENGTH || aresp[0] != byte(SecurityAuthentication) {
 		return fmt.Errorf("invalid stoc hash response")
 	}
-	r, err := s.gcm.Encrypt(d.cryptbuffer, aresp[0], binary.BigEndian.Uint32(aresp[1:]), d.aareres.SystemTitle, s.CtoS)
+	r, err := s.gcm.AuthTag(aresp[0], binary.BigEndian.Uint32(aresp[1:]), d.aareres.SystemTitle, s.CtoS)
 	if err != nil {
 		return err
 	}
-	if len(r) < gcm.GCM_TAG_LENGTH {
-		return fmt.Errorf("probably programatic error")
-	}
 
-	if bytes.Equal(aresp[5:], r[len(r)-gcm.GCM_TAG_LENGTH:]) {
+	if bytes.Equal(aresp[5:], r) {
+		d.authpending = false
 		return nil
 	}
 
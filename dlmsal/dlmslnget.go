@@ -19,8 +19,18 @@ type dlmsalget struct { // this will implement io.Reader for LN Get operation
 	data      []DlmsData
 	blockexp  uint32
 	lastblock bool
+	blocks    uint32 // received blocks in this transfer
 	remaining uint
 	transport io.Reader
+	blocksize uint // size of the current block, needed for resume
+	skip      uint // already consumed part of the block after resume
+
+	echo []byte // descriptor of single item request, for TolerateEchoedDescriptor
+}
+
+type getresume struct { // interrupted in memory block transfer
+	ln  *dlmsalget
+	mem ChunkedStream
 }
 
 func encodelncosemattr(dst *bytes.Buffer, item *DlmsLNRequestItem) {
@@ -64,8 +74,7 @@ func (ln *dlmsalget) get(items []DlmsLNRequestItem) ([]DlmsData, error) {
 	} else {
 		local.WriteByte(byte(TagGetRequestNormal))
 	}
-	master.invokeid = (master.invokeid + 1) & 7
-	local.WriteByte(master.invokeid | master.settings.invokebyte)
+	local.WriteByte(master.nextinvoke())
 
 	if len(items) > 1 {
 		encodelength(local, uint(len(items)))
@@ -77,6 +86,7 @@ func (ln *dlmsalget) get(items []DlmsLNRequestItem) ([]DlmsData, error) {
 			return nil, err
 		}
 	}
+	ln.setecho(items)
 
 	// send itself, that could be fun, do that in one step for now
 	tag, str, err := master.sendpdu()
@@ -106,13 +116,13 @@ func (ln *dlmsalget) getstream(item DlmsLNRequestItem, inmem bool) (DlmsDataStre
 	local.Reset()
 	local.WriteByte(byte(TagGetRequest))
 	local.WriteByte(byte(TagGetRequestNormal))
-	master.invokeid = (master.invokeid + 1) & 7
-	local.WriteByte(master.invokeid | master.settings.invokebyte)
+	local.WriteByte(master.nextinvoke())
 
 	err := encodelngetitem(local, &item)
 	if err != nil {
 		return nil, err
 	}
+	ln.setecho([]DlmsLNRequestItem{item})
 
 	// send itself, that could be fun, do that in one step for now
 	tag, str, err := master.sendpdu()
@@ -142,19 +152,23 @@ func (ln *dlmsalget) getstreamdata(tag CosemTag, inmem bool) (s DlmsDataStream,
 		return nil, err
 	}
 
-	if master.tmpbuffer[1]&7 != master.invokeid {
+	if !master.checkinvoke(master.tmpbuffer[1]) {
 		return nil, fmt.Errorf("unexpected invoke id")
 	}
 
 	switch getResponseTag(master.tmpbuffer[0]) {
 	case TagGetResponseNormal:
+		ln.skipecho()
 		// decode data themselves
 		_, err = io.ReadFull(ln.transport, master.tmpbuffer[:1])
 		if err != nil {
 			return nil, err
 		}
 
-		if master.tmpbuffer[0] != 0 {
+		src := ln.transport
+		if master.tmpbuffer[0] > 1 && master.settings.TolerateMissingResultByte && isdatatag(master.tmpbuffer[0]) {
+			src = io.MultiReader(bytes.NewReader([]byte{master.tmpbuffer[0]}), ln.transport) // give the tag back
+		} else if master.tmpbuffer[0] != 0 {
 			_, err = io.ReadFull(ln.transport, master.tmpbuffer[:1])
 			if err != nil {
 				if errors.Is(err, io.ErrUnexpectedEOF) {
@@ -164,13 +178,22 @@ func (ln *dlmsalget) getstreamdata(tag CosemTag, inmem bool) (s DlmsDataStream,
 			}
 			return nil, NewDlmsError(DlmsResultTag(master.tmpbuffer[0]))
 		}
-		str, err := newDataStream(ln.transport, inmem, master.logger)
+		str, err := newDataStream(src, inmem, master.logger)
 		if err != nil {
 			return nil, err
 		}
 		return str, nil
 	case TagGetResponseWithDataBlock: // this is a bit of hell, read till eof from lower layer and then ask for next block and so on
 		ln.state = 1
+		if inmem { // keep what was already read, so it can be resumed
+			mem := NewChunkedStream()
+			err = mem.CopyFrom(ln)
+			if err != nil {
+				master.resume = &getresume{ln: ln, mem: mem}
+				return nil, err
+			}
+			return newMemDataStream(mem, master.logger), nil
+		}
 		str, err := newDataStream(ln, inmem, master.logger)
 		if err != nil {
 			return nil, err
@@ -201,7 +224,7 @@ func (ln *dlmsalget) getnextdata(tag CosemTag, i int) (cont bool, err error) {
 			return false, err
 		}
 
-		if master.tmpbuffer[1]&7 != master.invokeid {
+		if !master.checkinvoke(master.tmpbuffer[1]) {
 			return false, fmt.Errorf("unexpected invoke id")
 		}
 
@@ -210,13 +233,16 @@ func (ln *dlmsalget) getnextdata(tag CosemTag, i int) (cont bool, err error) {
 			if len(ln.data) > 1 {
 				return false, fmt.Errorf("expecting list response")
 			}
+			ln.skipecho()
 			// decode data themselves
 			_, err = io.ReadFull(ln.transport, master.tmpbuffer[:1])
 			if err != nil {
 				return false, err
 			}
 
-			if master.tmpbuffer[0] != 0 {
+			if master.tmpbuffer[0] > 1 && master.settings.TolerateMissingResultByte && isdatatag(master.tmpbuffer[0]) { // already the data tag
+				ln.data[i], _, err = decodeData(ln.transport, dataTag(master.tmpbuffer[0]), &master.tmpbuffer)
+			} else if master.tmpbuffer[0] != 0 {
 				_, err = io.ReadFull(ln.transport, master.tmpbuffer[:1])
 				if err != nil {
 					if errors.Is(err, io.ErrUnexpectedEOF) {
@@ -232,10 +258,7 @@ func (ln *dlmsalget) getnextdata(tag CosemTag, i int) (cont bool, err error) {
 			}
 			ln.state = 100
 			return false, err
-		case TagGetResponseWithList:
-			if len(ln.data) == 1 {
-				return false, fmt.Errorf("expecting normal response")
-			}
+		case TagGetResponseWithList: // some meters answer normal request with single item list
 			l, _, err := decodelength(ln.transport, &master.tmpbuffer)
 			if err != nil {
 				return false, err
@@ -290,6 +313,33 @@ func (ln *dlmsalget) getnextdata(tag CosemTag, i int) (cont bool, err error) {
 	return false, fmt.Errorf("program error, unexpected state: %v", ln.state)
 }
 
+func (ln *dlmsalget) setecho(items []DlmsLNRequestItem) {
+	ln.echo = nil
+	if len(items) == 1 && ln.master.settings.TolerateEchoedDescriptor {
+		var b bytes.Buffer
+		encodelncosemattr(&b, &items[0])
+		ln.echo = b.Bytes()
+	}
+}
+
+// some legacy meters repeat class, obis and attribute of the request in get-response-normal before the result,
+// it is skipped only if it matches the request exactly, otherwise read bytes are given back
+func (ln *dlmsalget) skipecho() {
+	if len(ln.echo) == 0 {
+		return
+	}
+	master := ln.master
+	tmp := master.tmpbuffer[:len(ln.echo)]
+	n, _ := io.ReadFull(ln.transport, tmp)
+	if n == len(tmp) && bytes.Equal(tmp, ln.echo) {
+		master.logf("echoed attribute descriptor in get response skipped")
+		return
+	}
+	if n > 0 {
+		ln.transport = io.MultiReader(bytes.NewReader(newcopy(tmp[:n])), ln.transport)
+	}
+}
+
 func (ln *dlmsalget) decodedata(i int) (err error) {
 	master := ln.master
 	_, err = io.ReadFull(ln, master.tmpbuffer[:1])
@@ -325,6 +375,7 @@ func (ln *dlmsalget) Read(p []byte) (n int, err error) { // this will go to data
 		}
 		blockno := (uint32(master.tmpbuffer[1]) << 24) | (uint32(master.tmpbuffer[2]) << 16) | (uint32(master.tmpbuffer[3]) << 8) | uint32(master.tmpbuffer[4])
 		ln.blockexp = blockno
+		ln.blocks = 1
 		ln.remaining, _, err = decodelength(ln.transport, &master.tmpbuffer) // refactor usage of these tmp buffers...
 		if err != nil {
 			return 0, err
@@ -332,6 +383,7 @@ func (ln *dlmsalget) Read(p []byte) (n int, err error) { // this will go to data
 		if ln.remaining == 0 {
 			return 0, fmt.Errorf("zero length block")
 		}
+		ln.blocksize = ln.remaining
 		ln.state = 2
 		if uint(len(p)) > ln.remaining {
 			p = p[:ln.remaining]
@@ -344,12 +396,15 @@ func (ln *dlmsalget) Read(p []byte) (n int, err error) { // this will go to data
 			if ln.lastblock {
 				return 0, io.EOF // or some common error?
 			}
+			if err = master.checkblocks("get", ln.blocks+1); err != nil {
+				return 0, err
+			}
 			// ask for the next block
 			local := &master.pdu
 			local.Reset()
 			local.WriteByte(byte(TagGetRequest))
 			local.WriteByte(byte(TagGetRequestNext))
-			local.WriteByte(master.invokeid | master.settings.invokebyte)
+			local.WriteByte(master.currentinvoke())
 			local.WriteByte(byte(ln.blockexp >> 24))
 			local.WriteByte(byte(ln.blockexp >> 16))
 			local.WriteByte(byte(ln.blockexp >> 8))
@@ -367,26 +422,47 @@ func (ln *dlmsalget) Read(p []byte) (n int, err error) { // this will go to data
 			if err != nil {
 				return 0, err
 			}
-			if master.tmpbuffer[0] != byte(TagGetResponseWithDataBlock) || master.tmpbuffer[1]&7 != master.invokeid {
+			if master.tmpbuffer[0] != byte(TagGetResponseWithDataBlock) || !master.checkinvoke(master.tmpbuffer[1]) {
 				return 0, fmt.Errorf("unexpected response tag: %02x", master.tmpbuffer[0])
 			}
-			// set last, check block number and set remaining
-			ln.lastblock = master.tmpbuffer[2] != 0
+			// set last, check block number and set remaining, state is changed only after whole header is read, so resume knows where to continue
+			lastblock := master.tmpbuffer[2] != 0
+			blockno := (uint32(master.tmpbuffer[3]) << 24) | (uint32(master.tmpbuffer[4]) << 16) | (uint32(master.tmpbuffer[5]) << 8) | uint32(master.tmpbuffer[6])
 			if master.tmpbuffer[7] != 0 {
-				return 0, fmt.Errorf("returned failed request, not handled, error: %v", master.tmpbuffer[7])
+				_, err = io.ReadFull(ln.transport, master.tmpbuffer[:1])
+				if err != nil {
+					return 0, fmt.Errorf("returned failed request, unable to read result: %w", err)
+				}
+				return 0, NewDlmsError(DlmsResultTag(master.tmpbuffer[0]))
 			}
-			ln.blockexp++
-			blockno := (uint32(master.tmpbuffer[3]) << 24) | (uint32(master.tmpbuffer[4]) << 16) | (uint32(master.tmpbuffer[5]) << 8) | uint32(master.tmpbuffer[6])
-			if ln.blockexp != blockno {
+			if ln.blockexp+1 != blockno {
 				return 0, fmt.Errorf("unexpected block number")
 			}
-			ln.remaining, _, err = decodelength(ln.transport, &master.tmpbuffer) // refactor usage of these tmp buffers...
+			remaining, _, err := decodelength(ln.transport, &master.tmpbuffer) // refactor usage of these tmp buffers...
 			if err != nil {
 				return 0, err
 			}
-			if ln.remaining == 0 {
+			if remaining == 0 {
 				return 0, fmt.Errorf("zero length block")
 			}
+			if ln.skip > remaining {
+				return 0, fmt.Errorf("resumed block is shorter than expected")
+			}
+			if ln.skip > 0 {
+				_, err = io.CopyN(io.Discard, ln.transport, int64(ln.skip))
+				if err != nil {
+					return 0, err
+				}
+			}
+			ln.blockexp = blockno
+			ln.blocks++
+			ln.lastblock = lastblock
+			ln.blocksize = remaining
+			ln.remaining = remaining - ln.skip
+			ln.skip = 0
+			if ln.remaining == 0 { // whole block was already consumed, just continue
+				return ln.Read(p)
+			}
 		}
 		if uint(len(p)) > ln.remaining {
 			p = p[:ln.remaining]
@@ -402,16 +478,154 @@ func (d *dlmsal) Get(items []DlmsLNRequestItem) ([]DlmsData, error) {
 	if !d.isopen {
 		return nil, base.ErrNotOpened
 	}
+	if d.authpending {
+		return nil, ErrAuthenticationPending
+	}
 
+	d.logitems("get", items)
 	ln := &dlmsalget{master: d, state: 0, blockexp: 0}
-	return ln.get(items)
+	ret, err := ln.get(items)
+	if err == nil && len(items) > 1 && len(ret) > 0 && pdutoolong(ret[0]) { // whole list doesnt fit, meter wants block transfer per item
+		d.logf("Get with list too long, falling back to single item requests")
+		for i := range items {
+			ln = &dlmsalget{master: d, state: 0, blockexp: 0}
+			var r []DlmsData
+			if r, err = ln.get(items[i : i+1]); err != nil {
+				return nil, err
+			}
+			ret[i] = r[0]
+		}
+	}
+	for i := range ret { // exception is shared by all items, so every item gets own copy of the error and of the item
+		if e, ok := ret[i].Value.(*DlmsError); ok && ret[i].Tag == TagError && i < len(items) && e.Item == nil {
+			ce := *e
+			item := items[i]
+			ce.Item = &item
+			ret[i].Value = &ce
+		}
+	}
+	return ret, err
+}
+
+// exception-response saying the response doesnt fit into the pdu
+func pdutoolong(data DlmsData) bool {
+	e, ok := data.Value.(*DlmsError)
+	return ok && data.Tag == TagError && e.Exception != nil && e.Exception.ServiceError == ExceptionServicePduTooLong
+}
+
+type dedupkey struct {
+	classId   uint16
+	obis      DlmsObis
+	attribute int8
+}
+
+// the same as Get, but identical items are requested only once, result has the same length and order as items,
+// duplicates share the same value (so dont modify slices inside), items with access selector are never merged
+func (d *dlmsal) GetDedup(items []DlmsLNRequestItem) ([]DlmsData, error) {
+	unique := make([]DlmsLNRequestItem, 0, len(items))
+	positions := make([]int, len(items)) // index into unique for each item
+	seen := make(map[dedupkey]int)
+	for i, it := range items {
+		if !it.HasAccess {
+			k := dedupkey{classId: it.ClassId, obis: it.Obis, attribute: it.Attribute}
+			if u, ok := seen[k]; ok {
+				positions[i] = u
+				continue
+			}
+			seen[k] = len(unique)
+		}
+		positions[i] = len(unique)
+		unique = append(unique, it)
+	}
+	if len(unique) == len(items) {
+		return d.Get(items)
+	}
+
+	data, err := d.Get(unique)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != len(unique) {
+		return nil, fmt.Errorf("unexpected number of results %d, expected %d", len(data), len(unique))
+	}
+	ret := make([]DlmsData, len(items))
+	for i, u := range positions {
+		ret[i] = data[u]
+		if e, ok := ret[i].Value.(*DlmsError); ok && ret[i].Tag == TagError { // every position gets own error pointing to own item
+			ce := *e
+			item := items[i]
+			ce.Item = &item
+			ret[i].Value = &ce
+		}
+	}
+	return ret, nil
+}
+
+// single item get which fails in case the result isnt of the expected top level tag, data access errors are returned as DlmsError
+func (d *dlmsal) GetExpect(item DlmsLNRequestItem, expectedTag dataTag) (DlmsData, error) {
+	data, err := d.Get([]DlmsLNRequestItem{item})
+	if err != nil {
+		return DlmsData{}, err
+	}
+	if e, ok := data[0].ResultError(); ok {
+		return DlmsData{}, e
+	}
+	if data[0].Tag != expectedTag {
+		return DlmsData{}, fmt.Errorf("unexpected tag %d of %s class %d attr %d, expected %d", data[0].Tag, item.Obis.String(), item.ClassId, item.Attribute, expectedTag)
+	}
+	return data[0], nil
 }
 
 func (d *dlmsal) GetStream(item DlmsLNRequestItem, inmem bool) (DlmsDataStream, error) {
 	if !d.isopen {
 		return nil, base.ErrNotOpened
 	}
+	if d.authpending {
+		return nil, ErrAuthenticationPending
+	}
+	d.resume = nil
 
+	d.logitems("get", []DlmsLNRequestItem{item})
 	ln := &dlmsalget{master: d, state: 0, blockexp: 0}
 	return ln.getstream(item, inmem)
 }
+
+// continue in memory GetStream which failed during block transfer, association is created again (transport is reopened)
+// and the meter is asked for the block where it ended, not every meter is able to do that after reconnect,
+// direct (not in memory) streams cant be resumed as already decoded data are gone
+func (d *dlmsal) ResumeGet() (DlmsDataStream, error) {
+	r := d.resume
+	if r == nil {
+		return nil, fmt.Errorf("no interrupted block transfer to resume")
+	}
+	ln := r.ln
+	if ln.state != 2 {
+		d.resume = nil
+		return nil, fmt.Errorf("unable to resume, no block received yet")
+	}
+	if ln.remaining != 0 { // ask for the same block again and throw away already read part
+		ln.skip = ln.blocksize - ln.remaining
+		ln.blockexp--
+		ln.remaining = 0
+		ln.lastblock = false
+	}
+
+	d.isopen = false
+	_ = d.transport.Disconnect()
+	err := d.Open()
+	if err != nil {
+		return nil, fmt.Errorf("unable to associate again: %w", err)
+	}
+
+	err = r.mem.CopyFrom(ln)
+	if err != nil {
+		var de *DlmsError
+		if errors.As(err, &de) {
+			d.resume = nil // meter refused, no way to continue
+			return nil, fmt.Errorf("meter refused to continue block transfer: %w", err)
+		}
+		return nil, err
+	}
+	d.resume = nil
+	return newMemDataStream(r.mem, d.logger), nil
+}
@@ -29,9 +29,8 @@ func (al *dlmsal) setsingle(item DlmsLNRequestItem) ([]DlmsResultTag, error) {
 	local := &al.pdu
 	local.Reset()
 	local.WriteByte(byte(TagSetRequest))
-	al.invokeid = (al.invokeid + 1) & 7
-	local.WriteByte(al.invokeid | al.settings.invokebyte)
 	local.WriteByte(byte(TagSetRequestNormal))
+	local.WriteByte(al.nextinvoke())
 	err := encodelnsetitem(local, &item)
 	if err != nil {
 		return nil, err
@@ -46,10 +45,13 @@ func (al *dlmsal) setsingle(item DlmsLNRequestItem) ([]DlmsResultTag, error) {
 	ret := make([]DlmsResultTag, 1)
 
 	if local.Len()+sdata.Len() > al.maxPduSendSize-6-gcm.GCM_TAG_LENGTH { // block transfer, count on 6 bytes for tag and worst length and tag, ok, possible byte wasting here
+		if al.unconfirmed() {
+			return nil, fmt.Errorf("unconfirmed set doesnt fit into a single pdu, block transfer needs answers")
+		}
 		local.Reset() // possible large memory allocated here, but only for one job
 		local.WriteByte(byte(TagSetRequest))
-		local.WriteByte(al.invokeid | al.settings.invokebyte)
 		local.WriteByte(byte(TagSetRequestWithFirstDataBlock))
+		local.WriteByte(al.currentinvoke())
 		_ = encodelnsetitem(local, &item)
 
 		if al.maxPduSendSize < 16+gcm.GCM_TAG_LENGTH+local.Len() {
@@ -59,6 +61,9 @@ func (al *dlmsal) setsingle(item DlmsLNRequestItem) ([]DlmsResultTag, error) {
 		blno := uint32(1)
 		last := false
 		for !last {
+			if err = al.checkblocks("set", blno); err != nil {
+				return nil, err
+			}
 			var ts int
 			if len(data) > al.maxPduSendSize-16-gcm.GCM_TAG_LENGTH-local.Len() { // 11 bytes for my length and possible gcm length
 				ts = al.maxPduSendSize - 16 - gcm.GCM_TAG_LENGTH - local.Len()
@@ -102,7 +107,7 @@ func (al *dlmsal) setsingle(item DlmsLNRequestItem) ([]DlmsResultTag, error) {
 			if err != nil {
 				return nil, err
 			}
-			if al.tmpbuffer[1]&7 != al.invokeid {
+			if !al.checkinvoke(al.tmpbuffer[1]) {
 				return nil, fmt.Errorf("unexpected invoke id")
 			}
 			switch setResponseTag(al.tmpbuffer[0]) {
@@ -116,8 +121,8 @@ func (al *dlmsal) setsingle(item DlmsLNRequestItem) ([]DlmsResultTag, error) {
 				// ask for another block
 				local.Reset()
 				local.WriteByte(byte(TagSetRequest))
-				local.WriteByte(al.invokeid | al.settings.invokebyte)
 				local.WriteByte(byte(TagSetRequestWithDataBlock))
+				local.WriteByte(al.currentinvoke())
 				blno++
 			case TagSetResponseLastDataBlock:
 				if !last {
@@ -137,6 +142,13 @@ func (al *dlmsal) setsingle(item DlmsLNRequestItem) ([]DlmsResultTag, error) {
 		}
 	} else { // continue with normal set
 		local.Write(sdata.Bytes())
+		if al.unconfirmed() { // no answer, so result is unknown, success is assumed once its sent
+			if err = al.sendunconfirmed(); err != nil {
+				return nil, err
+			}
+			ret[0] = TagResultSuccess
+			return ret, nil
+		}
 		tag, str, err := al.sendpdu()
 		if err != nil {
 			return nil, err
@@ -161,7 +173,7 @@ func (al *dlmsal) setsingle(item DlmsLNRequestItem) ([]DlmsResultTag, error) {
 		if al.tmpbuffer[0] != byte(TagSetResponseNormal) {
 			return nil, fmt.Errorf("unexpected tag: %02x, expected TagSetResponseNormal", al.tmpbuffer[0])
 		}
-		if al.tmpbuffer[1]&7 != al.invokeid {
+		if !al.checkinvoke(al.tmpbuffer[1]) {
 			return nil, fmt.Errorf("unexpected invoke id")
 		}
 
@@ -174,7 +186,11 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 	if !al.isopen {
 		return nil, base.ErrNotOpened
 	}
+	if al.authpending {
+		return nil, ErrAuthenticationPending
+	}
 
+	al.logitems("set", items)
 	// buffer request send it optionally using blocks and return result, no streaming here
 	switch len(items) {
 	case 0:
@@ -187,9 +203,8 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 	local := &al.pdu
 	local.Reset()
 	local.WriteByte(byte(TagSetRequest))
-	al.invokeid = (al.invokeid + 1) & 7
-	local.WriteByte(al.invokeid | al.settings.invokebyte)
 	local.WriteByte(byte(TagSetRequestWithList))
+	local.WriteByte(al.nextinvoke())
 	encodelength(local, uint(len(items)))
 	for _, i := range items {
 		err = encodelnsetitem(local, &i)
@@ -210,10 +225,13 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 	ret = make([]DlmsResultTag, len(items))
 
 	if local.Len()+sdata.Len() > al.maxPduSendSize-6-gcm.GCM_TAG_LENGTH { // block transfer, count on 6 bytes for tag and worst length and tag, ok, possible byte wasting here
+		if al.unconfirmed() {
+			return nil, fmt.Errorf("unconfirmed set doesnt fit into a single pdu, block transfer needs answers")
+		}
 		local.Reset()
 		local.WriteByte(byte(TagSetRequest))
-		local.WriteByte(al.invokeid | al.settings.invokebyte)
 		local.WriteByte(byte(TagSetRequestWithListAndFirstDataBlock)) // yes yes i can force content to this
+		local.WriteByte(al.currentinvoke())
 		encodelength(local, uint(len(items)))
 		for _, i := range items {
 			_ = encodelnsetitem(local, &i)
@@ -226,6 +244,9 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 		blno := uint32(1)
 		last := false
 		for !last {
+			if err = al.checkblocks("set", blno); err != nil {
+				return nil, err
+			}
 			var ts int
 			if len(data) > al.maxPduSendSize-16-gcm.GCM_TAG_LENGTH-local.Len() { // 11 bytes for my length and possible gcm length
 				ts = al.maxPduSendSize - 16 - gcm.GCM_TAG_LENGTH - local.Len()
@@ -271,7 +292,7 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 			if err != nil {
 				return nil, err
 			}
-			if al.tmpbuffer[1]&7 != al.invokeid {
+			if !al.checkinvoke(al.tmpbuffer[1]) {
 				return nil, fmt.Errorf("unexpected invoke id")
 			}
 			switch setResponseTag(al.tmpbuffer[0]) {
@@ -289,9 +310,17 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 				// ask for another block
 				local.Reset()
 				local.WriteByte(byte(TagSetRequest))
-				local.WriteByte(al.invokeid | al.settings.invokebyte)
 				local.WriteByte(byte(TagSetRequestWithDataBlock))
+				local.WriteByte(al.currentinvoke())
 				blno++
+			case TagSetResponseLastDataBlock: // single result for the whole list, block number follows it
+				if !last {
+					return nil, fmt.Errorf("expected data block tag, but not got")
+				}
+				if !al.settings.TolerateSingleSetResult {
+					return nil, fmt.Errorf("unexpected tag: %02x, expected TagSetResponseLastDataBlockWithList", al.tmpbuffer[0])
+				}
+				return al.singlesetresult(str, ret)
 			case TagSetResponseLastDataBlockWithList:
 				if !last {
 					return nil, fmt.Errorf("expected data block tag, but not got")
@@ -325,6 +354,15 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 		}
 	} else { // continue with normal list set
 		local.Write(sdata.Bytes())
+		if al.unconfirmed() { // no answer, so results are unknown, success is assumed once its sent
+			if err = al.sendunconfirmed(); err != nil {
+				return nil, err
+			}
+			for i := range ret {
+				ret[i] = TagResultSuccess
+			}
+			return ret, nil
+		}
 		tag, str, err := al.sendpdu()
 		if err != nil {
 			return nil, err
@@ -348,10 +386,16 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 		if err != nil {
 			return nil, err
 		}
+		if al.tmpbuffer[0] == byte(TagSetResponseNormal) && al.settings.TolerateSingleSetResult {
+			if !al.checkinvoke(al.tmpbuffer[1]) {
+				return nil, fmt.Errorf("unexpected invoke id")
+			}
+			return al.singlesetresult(str, ret)
+		}
 		if al.tmpbuffer[0] != byte(TagSetResponseWithList) {
 			return nil, fmt.Errorf("unexpected tag: %02x, expected TagSetResponseWithList", al.tmpbuffer[0])
 		}
-		if al.tmpbuffer[1]&7 != al.invokeid {
+		if !al.checkinvoke(al.tmpbuffer[1]) {
 			return nil, fmt.Errorf("unexpected invoke id")
 		}
 		var l uint
@@ -378,3 +422,15 @@ func (al *dlmsal) Set(items []DlmsLNRequestItem) (ret []DlmsResultTag, err error
 	}
 	return ret, nil
 }
+
+// some meters answer set with list with a single result, it is applied to all items
+func (al *dlmsal) singlesetresult(str io.Reader, ret []DlmsResultTag) ([]DlmsResultTag, error) {
+	_, err := io.ReadFull(str, al.tmpbuffer[:1])
+	if err != nil {
+		return nil, err
+	}
+	for i := range ret {
+		ret[i] = DlmsResultTag(al.tmpbuffer[0])
+	}
+	return ret, nil
+}
@@ -0,0 +1,49 @@
+package dlmsal
+
+import (
+	"fmt"
+)
+
+// names of structure components in order, empty name means skip that component
+type Schema []string
+
+func (d *dlmsal) GetTyped(item DlmsLNRequestItem, schema Schema) (map[string]Value, error) {
+	data, err := d.Get([]DlmsLNRequestItem{item})
+	if err != nil {
+		return nil, err
+	}
+	return schema.Apply(data[0])
+}
+
+func (s Schema) Apply(data DlmsData) (map[string]Value, error) {
+	if data.Tag == TagError {
+		return nil, data.Value.(error)
+	}
+	if data.Tag != TagStructure {
+		return nil, fmt.Errorf("expected structure, got tag %d", data.Tag)
+	}
+	items, ok := data.Value.([]DlmsData)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", data.Value)
+	}
+	if len(items) != len(s) {
+		return nil, fmt.Errorf("schema has %d components, but structure has %d", len(s), len(items))
+	}
+
+	ret := make(map[string]Value, len(s))
+	for i, name := range s {
+		if len(name) == 0 {
+			continue
+		}
+		if _, ok := ret[name]; ok {
+			return nil, fmt.Errorf("duplicate schema name %s", name)
+		}
+		var v Value
+		err := Cast(&v, items[i])
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert component %s: %w", name, err)
+		}
+		ret[name] = v
+	}
+	return ret, nil
+}
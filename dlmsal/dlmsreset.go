@@ -0,0 +1,33 @@
+package dlmsal
+
+import (
+	"time"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+const sessionDrainTime = 500 * time.Millisecond
+
+// recovers client side after protocol error in the middle of operation without new association (frame counter just continues):
+// rest of the interrupted response is thrown away (base.Drain, at most for a short window), pending resumable get and request flags are reset.
+// Invoke ids of unanswered requests stay reserved, so late answers cant be taken for answers of next requests.
+// It doesnt guarantee meter forgot unfinished block transfer, and transport deadline is cleared, so set it again if needed.
+// Failing transport during the drain is returned as error.
+func (d *dlmsal) ResetSession() error {
+	if !d.isopen {
+		return base.ErrNotOpened
+	}
+
+	if err := base.Drain(d.transport, sessionDrainTime); err != nil {
+		return err
+	}
+
+	d.nextflags = nil
+	d.resume = nil
+	d.pdu.Reset()
+	d.lastsc = 0
+	d.lastciphered = false
+	d.lasttag = 0
+	d.lastinvoke = 0
+	return nil
+}
@@ -0,0 +1,84 @@
+package dlmsal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+type ScanRange struct {
+	Clients  []byte
+	Logical  []uint16
+	Physical []uint16
+	Timeout  time.Duration // per single attempt, zero means no timeout
+}
+
+type FoundDevice struct {
+	Client           byte
+	Logical          uint16
+	Physical         uint16
+	Associated       bool // association accepted, otherwise meter responded but refused
+	Result           AssociationResult
+	SourceDiagnostic SourceDiagnostic
+	Err              error // why association failed
+}
+
+// tries all combinations of addresses and returns ones where meter answered with aare, settings are copied for every attempt,
+// so framecounter isnt shared, usually it is meant for public client without ciphering
+func ScanAddresses(ctx context.Context, transportFactory func(client byte, logical, physical uint16) base.Stream, settings *DlmsSettings, scan *ScanRange) ([]FoundDevice, error) {
+	if transportFactory == nil || settings == nil || scan == nil {
+		return nil, fmt.Errorf("missing factory, settings or scan range")
+	}
+	if len(scan.Clients) == 0 || len(scan.Logical) == 0 || len(scan.Physical) == 0 {
+		return nil, fmt.Errorf("empty scan range")
+	}
+
+	ret := make([]FoundDevice, 0)
+	for _, client := range scan.Clients {
+		for _, logical := range scan.Logical {
+			for _, physical := range scan.Physical {
+				if err := ctx.Err(); err != nil {
+					return ret, err
+				}
+				f, ok := scanone(ctx, transportFactory(client, logical, physical), settings, scan.Timeout)
+				if ok {
+					f.Client = client
+					f.Logical = logical
+					f.Physical = physical
+					ret = append(ret, f)
+				}
+			}
+		}
+	}
+	return ret, nil
+}
+
+func scanone(ctx context.Context, t base.Stream, settings *DlmsSettings, timeout time.Duration) (f FoundDevice, ok bool) {
+	if t == nil {
+		return
+	}
+	deadline := time.Time{}
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	if cd, has := ctx.Deadline(); has && (deadline.IsZero() || cd.Before(deadline)) {
+		deadline = cd
+	}
+	t.SetDeadline(deadline)
+
+	s := *settings
+	d := New(t, &s).(*dlmsal)
+	err := d.Open()
+	if err == nil {
+		_ = d.Close()
+		_ = t.Disconnect()
+		return FoundDevice{Associated: true, Result: AssociationResultAccepted, SourceDiagnostic: d.aareres.SourceDiagnostic}, true
+	}
+	_ = t.Disconnect()
+	if d.aareres.ApplicationContextName == 0 { // there was no parsed aare, so nobody is there (or at least doesnt talk dlms)
+		return
+	}
+	return FoundDevice{Result: d.aareres.AssociationResult, SourceDiagnostic: d.aareres.SourceDiagnostic, Err: err}, true
+}
@@ -0,0 +1,64 @@
+package dlmsal
+
+import (
+	"fmt"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+const (
+	snAssociationBaseName = 0xfa00                                           // current association (class 12)
+	snObjectListAddress   = int16(snAssociationBaseName + (2-1)*8 - 0x10000) // object_list (attribute 2) at 0xfa08, the same rule as ResolveSN
+)
+
+type snobjectkey struct {
+	classid uint16
+	obis    DlmsObis
+}
+
+type snobject struct {
+	BaseName    int16
+	ClassId     uint16
+	Version     byte
+	LogicalName DlmsObis
+}
+
+// returns short name address of given attribute, object list is read once and cached for this client, attribute n is at base_name + (n-1)*8
+func (d *dlmsal) ResolveSN(classId uint16, obis DlmsObis, attr int8) (int16, error) {
+	if attr < 1 {
+		return 0, fmt.Errorf("invalid attribute %d", attr)
+	}
+	if d.snobjects == nil {
+		if !d.isopen {
+			return 0, base.ErrNotOpened
+		}
+		if err := d.loadsnobjects(); err != nil {
+			return 0, err
+		}
+	}
+	bn, ok := d.snobjects[snobjectkey{classid: classId, obis: obis}]
+	if !ok {
+		return 0, fmt.Errorf("object %d %s not found in object list", classId, obis.String())
+	}
+	return int16(uint16(bn) + uint16(attr-1)*8), nil // wraps around in the same way as meter does
+}
+
+func (d *dlmsal) loadsnobjects() error {
+	data, err := d.Read([]DlmsSNRequestItem{{Address: snObjectListAddress}})
+	if err != nil {
+		return err
+	}
+	if e, ok := data[0].ResultError(); ok {
+		return fmt.Errorf("unable to read object list: %w", e)
+	}
+	var list []snobject
+	if err = Cast(&list, data[0]); err != nil {
+		return fmt.Errorf("unable to decode object list: %w", err)
+	}
+	m := make(map[snobjectkey]int16, len(list))
+	for _, o := range list {
+		m[snobjectkey{classid: o.ClassId, obis: o.LogicalName}] = o.BaseName
+	}
+	d.snobjects = m
+	return nil
+}
@@ -1,18 +1,43 @@
 package dlmsal
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
 
+	"github.com/cybroslabs/libdlms-go/base"
 	"github.com/cybroslabs/libdlms-go/gcm"
 )
 
 // send and optionally encrypt packet at pdu to transport layer, returns also answer stream object with transparent ciphering and tag reading, hell
 func (d *dlmsal) sendpdu() (tag CosemTag, str io.Reader, err error) {
+	if err = d.writepdu(); err != nil {
+		return
+	}
+	return d.recvpdu()
+}
+
+// set/action request with confirmed bit cleared in invoke-id-and-priority, meter doesnt answer at all
+func (d *dlmsal) unconfirmed() bool {
+	b := d.pdu.Bytes()
+	return len(b) > 2 && b[2]&0x40 == 0
+}
+
+// sends pdu without waiting for any answer, transport is flushed as no read follows (hdlc would keep the last I frame otherwise)
+func (d *dlmsal) sendunconfirmed() error {
+	if err := d.writepdu(); err != nil {
+		return err
+	}
+	return base.Flush(d.transport)
+}
+
+// just send and optionally encrypt packet at pdu, used directly for unconfirmed requests
+func (d *dlmsal) writepdu() (err error) {
+	var tag CosemTag
 	local := &d.pdu
 	if local.Len() == 0 {
-		return tag, nil, fmt.Errorf("empty pdu")
+		return fmt.Errorf("empty pdu")
 	}
 	b := local.Bytes()
 	s := d.settings
@@ -29,7 +54,7 @@ func (d *dlmsal) sendpdu() (tag CosemTag, str io.Reader, err error) {
 		case TagWriteRequest:
 			tag = TagDedWriteRequest
 		default:
-			return tag, nil, fmt.Errorf("unsupported tag %v", b[0])
+			return fmt.Errorf("unsupported tag %v", b[0])
 		}
 		b = d.encryptpacket(byte(tag), b, true)
 	} else if s.gcm != nil {
@@ -45,34 +70,105 @@ func (d *dlmsal) sendpdu() (tag CosemTag, str io.Reader, err error) {
 		case TagWriteRequest:
 			tag = TagGloWriteRequest
 		default:
-			return tag, nil, fmt.Errorf("unsupported tag %v", b[0])
+			return fmt.Errorf("unsupported tag %v", b[0])
 		}
 		b = d.encryptpacket(byte(tag), b, false)
 	}
 
 	if len(b) > d.maxPduSendSize && d.maxPduSendSize != 0 {
-		return tag, nil, fmt.Errorf("PDU size exceeds maximum size: %v > %v", len(b), d.maxPduSendSize)
+		return fmt.Errorf("PDU size exceeds maximum size: %v > %v", len(b), d.maxPduSendSize)
 	}
-	err = d.transport.Write(b)
-	if err != nil {
-		return
+	d.lastciphered = false
+	d.lasttag = 0
+	d.lastinvoke = 0
+	if d.transcript != nil {
+		if s.dedgcm != nil || s.gcm != nil {
+			d.transcript.tx(local.Bytes(), b)
+		} else {
+			d.transcript.tx(b, nil)
+		}
+	}
+	if s.RedactCipheredLog && (s.dedgcm != nil || s.gcm != nil) { // tag byte stays so the log still says what it was
+		base.RedactNextWrite(d.transport, []base.Redaction{{Offset: 1, Length: len(b) - 1}})
 	}
-	// read first fucking byte, this is sooooo, fuuuuuu
-	_, err = io.ReadFull(d.transport, d.tmpbuffer[:1])
+	return d.transport.Write(b)
+}
+
+func (d *dlmsal) recvpdu() (tag CosemTag, str io.Reader, err error) {
+	tag, err = d.readtag(d.transport)
 	if err != nil {
 		return
 	}
-	tag = CosemTag(d.tmpbuffer[0])
+	var src io.Reader = d.transport
+	if tag == TagGeneralBlockTransfer { // whole apdu is assembled first, then it goes the usual way
+		src, err = d.recvgbt()
+		if err != nil {
+			return
+		}
+		tag, err = d.readtag(src)
+		if err != nil {
+			return
+		}
+	}
+	if d.transcript != nil {
+		d.transcript.rxstart(byte(tag))
+		src = io.TeeReader(src, &d.transcript.raw)
+	}
 	switch tag {
 	case TagGloGetResponse, TagGloSetResponse, TagGloActionResponse, TagGloReadResponse, TagGloWriteResponse:
-		return d.recvcipheredpdu(tag, false)
+		tag, str, err = d.recvcipheredpdu(src, tag, false)
 	case TagDedGetResponse, TagDedSetResponse, TagDedActionResponse, TagDedReadResponse, TagDedWriteResponse:
-		return d.recvcipheredpdu(tag, true)
+		tag, str, err = d.recvcipheredpdu(src, tag, true)
+	default:
+		str = src
+	}
+	if err != nil {
+		return
 	}
-	return tag, d.transport, err
+	d.lasttag = tag
+	if d.transcript != nil {
+		d.transcript.ciphered = d.lastciphered
+		d.transcript.plain.WriteByte(byte(tag))
+		str = io.TeeReader(str, &d.transcript.plain)
+	}
+	if tag == TagConfirmedServiceError { // can come instead of any response, so handle it here
+		_, err = io.ReadFull(str, d.tmpbuffer[:3])
+		if err != nil {
+			return tag, nil, fmt.Errorf("unable to read confirmed service error: %w", err)
+		}
+		cse, _ := decodeConfirmedServiceError(d.tmpbuffer[:3])
+		return tag, nil, &cse
+	}
+	return d.checkassociation(tag, str)
 }
 
-func (d *dlmsal) recvcipheredpdu(rtag CosemTag, ded bool) (tag CosemTag, str io.Reader, err error) {
+// meter released association on its own (rlrq/rlre instead of response) or says there is none
+// (exception service not allowed with operation not possible), so repeating the request is useless,
+// other exceptions (e.g. service outside negotiated conformance) are left to the service itself
+func (d *dlmsal) checkassociation(tag CosemTag, str io.Reader) (CosemTag, io.Reader, error) {
+	switch tag {
+	case TagRLRQ, TagRLRE:
+		_, _ = io.Copy(io.Discard, str)
+		d.isopen = false
+		return tag, nil, fmt.Errorf("%w: unexpected tag %d", ErrAssociationLost, tag)
+	case TagExceptionResponse: // peek state and service error, service decodes the exception itself otherwise
+		n, _ := io.ReadFull(str, d.tmpbuffer[:2])
+		head := newcopy(d.tmpbuffer[:n])
+		str = io.MultiReader(bytes.NewReader(head), str)
+		if n != 2 || ExceptionStateError(head[0]) != ExceptionStateServiceNotAllowed || ExceptionServiceError(head[1]) != ExceptionServiceOperationNotPossible {
+			return tag, str, nil
+		}
+		ex, _ := decodeException(str, &d.tmpbuffer)
+		d.isopen = false
+		if e, ok := ex.Value.(*DlmsError); ok {
+			return tag, nil, fmt.Errorf("%w: %w", ErrAssociationLost, e)
+		}
+		return tag, nil, ErrAssociationLost
+	}
+	return tag, str, nil
+}
+
+func (d *dlmsal) recvcipheredpdu(src io.Reader, rtag CosemTag, ded bool) (tag CosemTag, str io.Reader, err error) {
 	tag = rtag
 	s := d.settings
 	var gcm gcm.Gcm
@@ -87,23 +183,50 @@ func (d *dlmsal) recvcipheredpdu(rtag CosemTag, ded bool) (tag CosemTag, str io.
 		}
 		gcm = s.gcm
 	}
-	l, _, err := decodelength(d.transport, &d.tmpbuffer)
+	l, _, err := decodelength(src, &d.tmpbuffer)
 	if err != nil {
 		return tag, nil, err
 	}
-	_, err = io.ReadFull(d.transport, d.tmpbuffer[:5])
+	_, err = io.ReadFull(src, d.tmpbuffer[:5])
 	if err != nil {
 		return tag, nil, fmt.Errorf("unable to read SC byte and frame counter")
 	}
+	d.lastsc = d.tmpbuffer[0]
+	d.lastciphered = true
 	fc := binary.BigEndian.Uint32(d.tmpbuffer[1:])
-	str, err = gcm.GetDecryptorStream(d.tmpbuffer[0], fc, d.aareres.SystemTitle, io.LimitReader(d.transport, int64(l)))
+	str, err = gcm.GetDecryptorStream(d.tmpbuffer[0], fc, d.aareres.SystemTitle, io.LimitReader(src, int64(l)))
 	if err != nil {
 		return
 	}
-	_, err = io.ReadFull(str, d.tmpbuffer[:1])
+	tag, err = d.readtag(str)
+	return
+}
+
+func (d *dlmsal) readtag(src io.Reader) (CosemTag, error) {
+	_, err := io.ReadFull(src, d.tmpbuffer[:1])
 	if err != nil {
-		return
+		return 0, err
 	}
-	tag = CosemTag(d.tmpbuffer[0])
-	return
+	return CosemTag(d.tmpbuffer[0]), nil
+}
+
+// security control byte of the last response, ok is false in case it wasn't ciphered at all
+func (d *dlmsal) LastResponseSecurity() (sc byte, ok bool) {
+	return d.lastsc, d.lastciphered
+}
+
+// compares whole invoke id of the response with the last request and stores it for LastResponseInfo
+func (d *dlmsal) checkinvoke(b byte) bool {
+	d.lastinvoke = b
+	if b&0x0f != d.invokeid {
+		return false
+	}
+	d.answered = true
+	d.unanswered &^= 1 << d.invokeid
+	return true
+}
+
+// plain (deciphered) tag and invoke-id-and-priority byte of the last response, zeroes if not known
+func (d *dlmsal) LastResponseInfo() (tag CosemTag, invokeId byte) {
+	return d.lasttag, d.lastinvoke
 }
@@ -0,0 +1,7 @@
+package dlmsal
+
+import "errors"
+
+var ErrTooManyBlocks = errors.New("too many blocks in a single transfer")
+var ErrAuthenticationPending = errors.New("hls authentication required, call LNAuthentication first")
+var ErrAssociationLost = errors.New("association lost, meter released it, Open has to be called again")
@@ -0,0 +1,46 @@
+package dlmsal
+
+import (
+	"fmt"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+const (
+	invocationCounterClassId = 1 // data
+	invocationCounterAttr    = 2
+)
+
+// reads invocation counter (usually 0.0.43.1.x.255) using short no authentication association, transport has to be set up for public client (usually client address 16)
+// and it is closed afterwards, ciphered association has to use returned value + 1 as its frame counter
+func ReadInvocationCounter(transport base.Stream, obis DlmsObis) (uint32, error) {
+	settings, err := NewSettingsNoAuthenticationLN()
+	if err != nil {
+		return 0, err
+	}
+	dlms := New(transport, settings)
+	if err = dlms.Open(); err != nil {
+		_ = transport.Disconnect()
+		return 0, err
+	}
+
+	data, err := dlms.Get([]DlmsLNRequestItem{{ClassId: invocationCounterClassId, Obis: obis, Attribute: invocationCounterAttr}})
+	if err != nil {
+		_ = dlms.Disconnect()
+		return 0, err
+	}
+	if e, ok := data[0].ResultError(); ok {
+		_ = dlms.Close()
+		return 0, e
+	}
+	var fc uint32
+	if err = Cast(&fc, data[0]); err != nil {
+		_ = dlms.Close()
+		return 0, fmt.Errorf("unable to cast invocation counter: %w", err)
+	}
+
+	if err = dlms.Close(); err != nil { // counter is already read, release failure doesnt matter that much, just make sure transport is down
+		_ = dlms.Disconnect()
+	}
+	return fc, nil
+}
@@ -0,0 +1,118 @@
+package dlmsal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+type MeterTarget struct {
+	Name string // key in the result map, has to be unique
+	// settings are used directly (not copied), so framecounter is kept between runs, dont share one settings instance between targets
+	Settings *DlmsSettings
+	Items    []DlmsLNRequestItem
+}
+
+type MeterResult struct {
+	Data []DlmsData
+	Err  error // open, get or close failure, data can be still partially filled
+}
+
+type MeterReader struct {
+	factory     func(target *MeterTarget) (base.Stream, error)
+	concurrency int
+	timeout     time.Duration
+}
+
+// concurrency 0 or 1 means reading meters one by one, timeout is per meter (0 means no timeout)
+func NewMeterReader(factory func(target *MeterTarget) (base.Stream, error), concurrency int, timeout time.Duration) *MeterReader {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &MeterReader{
+		factory:     factory,
+		concurrency: concurrency,
+		timeout:     timeout,
+	}
+}
+
+// reads all targets, failure of one meter doesnt affect others, canceled context skips not yet started meters
+func (m *MeterReader) Read(ctx context.Context, targets []MeterTarget) (map[string]MeterResult, error) {
+	if m.factory == nil {
+		return nil, fmt.Errorf("no transport factory")
+	}
+	names := make(map[string]struct{}, len(targets))
+	for i := range targets {
+		if targets[i].Settings == nil {
+			return nil, fmt.Errorf("target %s has no settings", targets[i].Name)
+		}
+		if _, ok := names[targets[i].Name]; ok {
+			return nil, fmt.Errorf("duplicate target name %s", targets[i].Name)
+		}
+		names[targets[i].Name] = struct{}{}
+	}
+
+	ret := make(map[string]MeterResult, len(targets))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, m.concurrency)
+	for i := range targets {
+		t := &targets[i]
+		if err := ctx.Err(); err != nil {
+			mutex.Lock()
+			ret[t.Name] = MeterResult{Err: err}
+			mutex.Unlock()
+			continue
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r := m.readone(ctx, t)
+			mutex.Lock()
+			ret[t.Name] = r
+			mutex.Unlock()
+		}()
+	}
+	wg.Wait()
+	return ret, nil
+}
+
+func (m *MeterReader) readone(ctx context.Context, t *MeterTarget) (r MeterResult) {
+	defer func() {
+		if p := recover(); p != nil { // isolate also programming errors, batch has to go on
+			r.Err = fmt.Errorf("panic while reading meter %s: %v", t.Name, p)
+		}
+	}()
+
+	tr, err := m.factory(t)
+	if err != nil {
+		return MeterResult{Err: err}
+	}
+	if tr == nil {
+		return MeterResult{Err: fmt.Errorf("factory returned no transport")}
+	}
+	deadline := time.Time{}
+	if m.timeout > 0 {
+		deadline = time.Now().Add(m.timeout)
+	}
+	if cd, has := ctx.Deadline(); has && (deadline.IsZero() || cd.Before(deadline)) {
+		deadline = cd
+	}
+	tr.SetDeadline(deadline)
+	defer tr.Disconnect() // always tear down whole transport, also in case of panic
+
+	d := New(tr, t.Settings)
+	if err = d.Open(); err != nil {
+		return MeterResult{Err: err}
+	}
+	r.Data, r.Err = d.Get(t.Items)
+	if err = d.Close(); err != nil && r.Err == nil { // releasing association matters, otherwise meter can refuse next one
+		r.Err = err
+	}
+	return
+}
@@ -54,6 +54,9 @@ const (
 	TagDedSetResponse              CosemTag = 213
 	TagDedActionResponse           CosemTag = 215
 	TagExceptionResponse           CosemTag = 216
+	TagAccessRequest               CosemTag = 217
+	TagAccessResponse              CosemTag = 218
+	TagGeneralBlockTransfer        CosemTag = 224
 )
 
 type DlmsResultTag byte
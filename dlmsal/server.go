@@ -0,0 +1,741 @@
+package dlmsal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/cybroslabs/libdlms-go/base"
+	"go.uber.org/zap"
+)
+
+// callbacks of the server, nil callback means the service is denied for every object, access selector and its parameters are in item,
+// set data and action parameters are in item.SetData (nil for action without parameters)
+type ServerHandler struct {
+	Get    func(item DlmsLNRequestItem) (DlmsData, DlmsResultTag)
+	Set    func(item DlmsLNRequestItem) DlmsResultTag
+	Action func(item DlmsLNRequestItem) (*DlmsData, DlmsResultTag)
+}
+
+type ServerSettings struct {
+	Password         []byte // low authentication password, nil means only association without authentication is accepted
+	ConformanceBlock uint32 // what server supports, negotiated with the client proposal, zero means get, set, action, block transfers, selective access and multiple references
+	MaxPduRecvSize   uint16 // announced in aare, zero means 0xffff
+}
+
+type DlmsServer interface {
+	// answers requests till the transport returns io.EOF (nil is returned then), release doesnt stop it, so another association can follow
+	Serve() error
+	SetLogger(logger *zap.SugaredLogger)
+}
+
+const (
+	serverDefaultConformance = ConformanceBlockBlockTransferWithGetOrRead | ConformanceBlockBlockTransferWithSetOrWrite |
+		ConformanceBlockBlockTransferWithAction | ConformanceBlockAction | ConformanceBlockGet | ConformanceBlockSet |
+		ConformanceBlockSelectiveAccess | ConformanceBlockMultipleReferences
+	serverVAAddress = 0x0007 // ln referencing
+)
+
+type serverblocks struct { // pending outgoing block transfer (long get or action return parameters)
+	tag     CosemTag
+	data    []byte
+	blockno uint32
+}
+
+type serverset struct { // pending incoming set block transfer
+	items   []DlmsLNRequestItem
+	list    bool
+	blockno uint32
+	data    bytes.Buffer
+}
+
+type dlmsserver struct {
+	transport   base.Stream
+	logger      *zap.SugaredLogger
+	settings    ServerSettings
+	handler     ServerHandler
+	tmpbuffer   tmpbuffer
+	header      [8]byte
+	associated  bool
+	conformance uint32
+	maxsend     int
+	blocks      *serverblocks
+	set         *serverset
+}
+
+// meter side of ln association for emulators and tests over raw stream (tcp connection, one end of base.Pipe), apdus are framed
+// by the wrapper (iec 62056-47) header, hdlc and ciphered contexts are not supported, so it pairs with wrapper.New on the client side,
+// requests are answered one by one, so the server isnt reentrant
+func NewServer(transport base.Stream, settings *ServerSettings, handler *ServerHandler) DlmsServer {
+	s := &dlmsserver{transport: transport, settings: *settings, handler: *handler}
+	if s.settings.ConformanceBlock == 0 {
+		s.settings.ConformanceBlock = serverDefaultConformance
+	}
+	if s.settings.MaxPduRecvSize == 0 {
+		s.settings.MaxPduRecvSize = 0xffff
+	}
+	return s
+}
+
+func (s *dlmsserver) logf(format string, v ...any) {
+	if s.logger != nil {
+		s.logger.Infof(format, v...)
+	}
+}
+
+func (s *dlmsserver) SetLogger(logger *zap.SugaredLogger) {
+	s.logger = logger
+	s.transport.SetLogger(logger)
+}
+
+func (s *dlmsserver) Serve() error {
+	if err := s.transport.Open(); err != nil {
+		return err
+	}
+	for {
+		apdu, err := s.readapdu()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		resp := s.process(apdu)
+		if resp == nil { // unconfirmed service
+			continue
+		}
+		if err = s.writeapdu(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *dlmsserver) readapdu() ([]byte, error) {
+	_, err := io.ReadFull(s.transport, s.header[:])
+	if err != nil {
+		return nil, err
+	}
+	if s.header[0] != 0 || s.header[1] != 1 {
+		return nil, fmt.Errorf("invalid header version")
+	}
+	apdu := make([]byte, binary.BigEndian.Uint16(s.header[6:]))
+	_, err = io.ReadFull(s.transport, apdu)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if len(apdu) == 0 {
+		return nil, fmt.Errorf("empty apdu received")
+	}
+	return apdu, nil
+}
+
+// answer goes back with swapped wrapper ports
+func (s *dlmsserver) writeapdu(apdu []byte) error {
+	if len(apdu) > 0xffff {
+		return fmt.Errorf("apdu too long for wrapper")
+	}
+	out := make([]byte, 8+len(apdu))
+	out[1] = 1
+	copy(out[2:4], s.header[4:6])
+	copy(out[4:6], s.header[2:4])
+	binary.BigEndian.PutUint16(out[6:], uint16(len(apdu)))
+	copy(out[8:], apdu)
+	return s.transport.Write(out)
+}
+
+func (s *dlmsserver) process(apdu []byte) []byte {
+	tag := CosemTag(apdu[0])
+	switch tag {
+	case TagAARQ:
+		return s.associate(apdu)
+	case TagRLRQ:
+		s.associated = false
+		s.blocks = nil
+		s.set = nil
+		return []byte{byte(TagRLRE), 3, BERTypeContext, 1, 0} // normal
+	case TagGetRequest, TagSetRequest, TagActionRequest:
+		if !s.associated {
+			return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceOperationNotPossible)
+		}
+		r := bytes.NewReader(apdu[1:])
+		_, err := io.ReadFull(r, s.tmpbuffer[:2])
+		if err != nil {
+			return serverexception(ExceptionStateServiceUnknown, ExceptionServiceOtherReason)
+		}
+		var resp []byte
+		switch tag {
+		case TagGetRequest:
+			resp, err = s.get(getRequestTag(s.tmpbuffer[0]), s.tmpbuffer[1], r)
+		case TagSetRequest:
+			resp, err = s.setrequest(setRequestTag(s.tmpbuffer[0]), s.tmpbuffer[1], r)
+		default:
+			resp, err = s.action(actionRequestTag(s.tmpbuffer[0]), s.tmpbuffer[1], r)
+		}
+		if err != nil {
+			s.logf("Unable to process request %02x: %v", apdu[:min(len(apdu), 3)], err)
+			return serverexception(ExceptionStateServiceUnknown, ExceptionServiceOtherReason)
+		}
+		return resp
+	}
+	s.logf("Unsupported apdu tag: %02x", apdu[0])
+	return serverexception(ExceptionStateServiceUnknown, ExceptionServiceNotSupported)
+}
+
+func serverexception(state ExceptionStateError, service ExceptionServiceError) []byte {
+	return []byte{byte(TagExceptionResponse), byte(state), byte(service)}
+}
+
+func (s *dlmsserver) associate(apdu []byte) []byte {
+	s.associated = false
+	s.blocks = nil
+	s.set = nil
+
+	result := AssociationResultPermanentRejected
+	diag := SourceDiagnosticNoReasonGiven
+	var ctx ApplicationContext
+	mech := AuthenticationNone
+	var password []byte
+	var xdlms []byte
+
+	tag, _, data, err := decodetag(apdu, &s.tmpbuffer)
+	if err == nil && tag == byte(TagAARQ) {
+		var elements []AareTag
+		elements, err = decodeaare(data, &s.tmpbuffer)
+		for _, e := range elements {
+			switch e.Tag {
+			case BERTypeContext | BERTypeConstructed | PduTypeApplicationContextName:
+				if len(e.Data) > 0 {
+					ctx = ApplicationContext(e.Data[len(e.Data)-1])
+				}
+			case BERTypeContext | PduTypeMechanismName:
+				if len(e.Data) > 0 {
+					mech = Authentication(e.Data[len(e.Data)-1])
+				}
+			case BERTypeContext | BERTypeConstructed | PduTypeCallingAuthenticationValue:
+				if t, _, d, err := decodetag(e.Data, &s.tmpbuffer); err == nil {
+					switch {
+					case t == AuthValueCharString:
+						password = d
+					case t == AuthValueBitString && len(d) > 0:
+						password = d[1:]
+					}
+				}
+			case BERTypeContext | BERTypeConstructed | PduTypeUserInformation:
+				if t, _, d, err := decodetag(e.Data, &s.tmpbuffer); err == nil && t == 0x04 {
+					xdlms = d
+				}
+			}
+		}
+	}
+	conformance, maxpdu, xerr := parseinitiaterequest(xdlms)
+
+	switch {
+	case err != nil || xerr != nil:
+		s.logf("Invalid aarq: %v %v", err, xerr)
+	case ctx != ApplicationContextLNNoCiphering:
+		diag = SourceDiagnosticApplicationContextNameNotSupported
+	case s.settings.Password == nil && mech != AuthenticationNone:
+		diag = SourceDiagnosticAuthenticationMechanismNameNotRecognized
+	case s.settings.Password != nil && mech == AuthenticationNone:
+		diag = SourceDiagnosticAuthenticationMechanismNameRequired
+	case s.settings.Password != nil && mech != AuthenticationLow:
+		diag = SourceDiagnosticAuthenticationMechanismNameNotRecognized
+	case s.settings.Password != nil && !bytes.Equal(password, s.settings.Password):
+		diag = SourceDiagnosticAuthenticationFailure
+	default:
+		result = AssociationResultAccepted
+		diag = SourceDiagnosticNone
+	}
+
+	var content bytes.Buffer
+	content.Write([]byte{BERTypeContext | BERTypeConstructed | PduTypeApplicationContextName, 0x09, 0x06, 0x07, 0x60, 0x85, 0x74, 0x05, 0x08, 0x01, byte(ApplicationContextLNNoCiphering)})
+	content.Write([]byte{BERTypeContext | BERTypeConstructed | PduTypeCalledAPTitle, 0x03, 0x02, 0x01, byte(result)})
+	content.Write([]byte{BERTypeContext | BERTypeConstructed | PduTypeCalledAEQualifier, 0x05, 0xa1, 0x03, 0x02, 0x01, byte(diag)}) // acse-service-user
+	if result == AssociationResultAccepted {
+		s.associated = true
+		s.conformance = conformance & s.settings.ConformanceBlock
+		s.maxsend = int(maxpdu)
+		if s.maxsend == 0 {
+			s.maxsend = 0xffff
+		}
+		ir := make([]byte, 14)
+		ir[0] = byte(TagInitiateResponse)
+		ir[1] = 0 // no quality of service
+		ir[2] = DlmsVersion
+		ir[3] = 0x5f
+		ir[4] = 0x1f
+		ir[5] = 0x04
+		binary.BigEndian.PutUint32(ir[6:], s.conformance) // leading zero is unused bits count
+		binary.BigEndian.PutUint16(ir[10:], s.settings.MaxPduRecvSize)
+		binary.BigEndian.PutUint16(ir[12:], serverVAAddress)
+		encodetag2(&content, BERTypeContext|BERTypeConstructed|PduTypeUserInformation, 0x04, ir)
+	}
+	s.logf("Association result: %v, diagnostic: %v", result, diag)
+
+	var out bytes.Buffer
+	encodetag(&out, byte(TagAARE), content.Bytes())
+	return out.Bytes()
+}
+
+// initiate request from aarq user information, returns proposed conformance and client max receive pdu size
+func parseinitiaterequest(b []byte) (conformance uint32, maxpdu uint16, err error) {
+	if len(b) < 1 || b[0] != byte(TagInitiateRequest) {
+		return 0, 0, fmt.Errorf("no initiate request")
+	}
+	b = b[1:]
+	for i := 0; i < 3; i++ { // dedicated key, response allowed and quality of service, all optional
+		if len(b) < 1 {
+			return 0, 0, fmt.Errorf("initiate request too short")
+		}
+		if b[0] == 0 {
+			b = b[1:]
+			continue
+		}
+		if len(b) < 2 {
+			return 0, 0, fmt.Errorf("initiate request too short")
+		}
+		if i == 0 { // dedicated key is octet string
+			if len(b) < 2+int(b[1]) {
+				return 0, 0, fmt.Errorf("initiate request too short")
+			}
+			b = b[2+int(b[1]):]
+		} else {
+			b = b[2:]
+		}
+	}
+	if len(b) < 10 {
+		return 0, 0, fmt.Errorf("initiate request too short")
+	}
+	if b[0] < DlmsVersion {
+		return 0, 0, fmt.Errorf("unsupported dlms version %d", b[0])
+	}
+	if !bytes.Equal(b[1:4], []byte{0x5f, 0x1f, 0x04}) {
+		return 0, 0, fmt.Errorf("invalid conformance block")
+	}
+	conformance = binary.BigEndian.Uint32(b[4:8]) & 0xffffff
+	maxpdu = binary.BigEndian.Uint16(b[8:10])
+	return
+}
+
+func (s *dlmsserver) readitem(r *bytes.Reader, withaccess bool) (item DlmsLNRequestItem, err error) {
+	_, err = io.ReadFull(r, s.tmpbuffer[:9])
+	if err != nil {
+		return
+	}
+	item.ClassId = binary.BigEndian.Uint16(s.tmpbuffer[:])
+	item.Obis, _ = NewDlmsObisFromSlice(s.tmpbuffer[2:8])
+	item.Attribute = int8(s.tmpbuffer[8])
+	if !withaccess {
+		return
+	}
+	_, err = io.ReadFull(r, s.tmpbuffer[:1])
+	if err != nil || s.tmpbuffer[0] == 0 {
+		return
+	}
+	_, err = io.ReadFull(r, s.tmpbuffer[:1])
+	if err != nil {
+		return
+	}
+	item.HasAccess = true
+	item.AccessDescriptor = s.tmpbuffer[0]
+	ad, _, err := decodeDataTag(r, &s.tmpbuffer)
+	item.AccessData = &ad
+	return
+}
+
+func (s *dlmsserver) readitems(r *bytes.Reader, withaccess bool) ([]DlmsLNRequestItem, error) {
+	l, _, err := decodelength(r, &s.tmpbuffer)
+	if err != nil {
+		return nil, err
+	}
+	if l == 0 || l > uint(r.Len()) {
+		return nil, fmt.Errorf("invalid item count %d", l)
+	}
+	items := make([]DlmsLNRequestItem, l)
+	for i := range items {
+		if items[i], err = s.readitem(r, withaccess); err != nil {
+			return nil, err
+		}
+	}
+	return items, nil
+}
+
+func (s *dlmsserver) callget(item DlmsLNRequestItem) (DlmsData, DlmsResultTag) {
+	if s.handler.Get == nil {
+		return DlmsData{}, TagResultReadWriteDenied
+	}
+	return s.handler.Get(item)
+}
+
+func (s *dlmsserver) callset(item DlmsLNRequestItem) DlmsResultTag {
+	if s.handler.Set == nil {
+		return TagResultReadWriteDenied
+	}
+	return s.handler.Set(item)
+}
+
+func (s *dlmsserver) get(t getRequestTag, invoke byte, r *bytes.Reader) ([]byte, error) {
+	if s.conformance&ConformanceBlockGet == 0 {
+		return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceNotSupported), nil
+	}
+	var out bytes.Buffer
+	switch t {
+	case TagGetRequestNormal:
+		s.blocks = nil
+		item, err := s.readitem(r, true)
+		if err != nil {
+			return nil, err
+		}
+		data, res := s.callget(item)
+		out.Write([]byte{byte(TagGetResponse), byte(TagGetResponseNormal), invoke})
+		if res != TagResultSuccess {
+			out.Write([]byte{1, byte(res)})
+			return out.Bytes(), nil
+		}
+		var raw bytes.Buffer
+		if err = encodeData(&raw, &data); err != nil {
+			s.logf("Unable to encode get data: %v", err)
+			out.Write([]byte{1, byte(TagResultOtherReason)})
+			return out.Bytes(), nil
+		}
+		if out.Len()+1+raw.Len() > s.maxsend {
+			return s.startblocks(TagGetResponse, invoke, raw.Bytes())
+		}
+		out.WriteByte(0)
+		out.Write(raw.Bytes())
+		return out.Bytes(), nil
+	case TagGetRequestWithList:
+		s.blocks = nil
+		if s.conformance&ConformanceBlockMultipleReferences == 0 {
+			return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceNotSupported), nil
+		}
+		items, err := s.readitems(r, true)
+		if err != nil {
+			return nil, err
+		}
+		var raw bytes.Buffer
+		encodelength(&raw, uint(len(items)))
+		for _, item := range items {
+			data, res := s.callget(item)
+			if res == TagResultSuccess {
+				l := raw.Len()
+				raw.WriteByte(0)
+				if err = encodeData(&raw, &data); err == nil {
+					continue
+				}
+				s.logf("Unable to encode get data: %v", err)
+				raw.Truncate(l)
+				res = TagResultOtherReason
+			}
+			raw.Write([]byte{1, byte(res)})
+		}
+		out.Write([]byte{byte(TagGetResponse), byte(TagGetResponseWithList), invoke})
+		if out.Len()+raw.Len() > s.maxsend {
+			return s.startblocks(TagGetResponse, invoke, raw.Bytes())
+		}
+		out.Write(raw.Bytes())
+		return out.Bytes(), nil
+	case TagGetRequestNext:
+		_, err := io.ReadFull(r, s.tmpbuffer[:4])
+		if err != nil {
+			return nil, err
+		}
+		blockno := binary.BigEndian.Uint32(s.tmpbuffer[:])
+		if s.blocks == nil || s.blocks.tag != TagGetResponse || s.blocks.blockno != blockno {
+			res := TagResultNoLongGetInProgress
+			if s.blocks != nil {
+				res = TagResultDataBlockNumberInvalid
+			}
+			s.blocks = nil
+			out.Write([]byte{byte(TagGetResponse), byte(TagGetResponseWithDataBlock), invoke, 1})
+			out.Write(s.tmpbuffer[:4])
+			out.Write([]byte{1, byte(res)})
+			return out.Bytes(), nil
+		}
+		return s.nextblock(invoke), nil
+	}
+	return serverexception(ExceptionStateServiceUnknown, ExceptionServiceNotSupported), nil
+}
+
+func (s *dlmsserver) startblocks(tag CosemTag, invoke byte, raw []byte) ([]byte, error) {
+	var cb uint32
+	if tag == TagGetResponse {
+		cb = ConformanceBlockBlockTransferWithGetOrRead
+	} else {
+		cb = ConformanceBlockBlockTransferWithAction
+	}
+	if s.conformance&cb == 0 || s.maxsend < 32 {
+		return serverexception(ExceptionStateServiceNotAllowed, ExceptionServicePduTooLong), nil
+	}
+	s.blocks = &serverblocks{tag: tag, data: raw}
+	return s.nextblock(invoke), nil
+}
+
+// next block of pending transfer, get block has additional choice byte (raw data)
+func (s *dlmsserver) nextblock(invoke byte) []byte {
+	b := s.blocks
+	b.blockno++
+	header := 11 // tag, type, invoke, last, block number and worst case length
+	if b.tag == TagGetResponse {
+		header++
+	}
+	chunk := min(len(b.data), s.maxsend-header)
+	last := chunk == len(b.data)
+
+	var out bytes.Buffer
+	out.WriteByte(byte(b.tag))
+	if b.tag == TagGetResponse {
+		out.WriteByte(byte(TagGetResponseWithDataBlock))
+	} else {
+		out.WriteByte(byte(TagActionResponseWithPBlock))
+	}
+	out.WriteByte(invoke)
+	if last {
+		out.WriteByte(1)
+	} else {
+		out.WriteByte(0)
+	}
+	out.Write([]byte{byte(b.blockno >> 24), byte(b.blockno >> 16), byte(b.blockno >> 8), byte(b.blockno)})
+	if b.tag == TagGetResponse {
+		out.WriteByte(0) // raw data
+	}
+	encodelength(&out, uint(chunk))
+	out.Write(b.data[:chunk])
+	b.data = b.data[chunk:]
+	if last {
+		s.blocks = nil
+	}
+	return out.Bytes()
+}
+
+func (s *dlmsserver) setrequest(t setRequestTag, invoke byte, r *bytes.Reader) ([]byte, error) {
+	if s.conformance&ConformanceBlockSet == 0 {
+		return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceNotSupported), nil
+	}
+	confirmed := invoke&0x40 != 0
+	switch t {
+	case TagSetRequestNormal:
+		s.set = nil
+		item, err := s.readitem(r, true)
+		if err != nil {
+			return nil, err
+		}
+		data, _, err := decodeDataTag(r, &s.tmpbuffer)
+		if err != nil {
+			return nil, err
+		}
+		item.SetData = &data
+		res := s.callset(item)
+		if !confirmed {
+			return nil, nil
+		}
+		return []byte{byte(TagSetResponse), byte(TagSetResponseNormal), invoke, byte(res)}, nil
+	case TagSetRequestWithList:
+		s.set = nil
+		if s.conformance&ConformanceBlockMultipleReferences == 0 {
+			return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceNotSupported), nil
+		}
+		items, err := s.readitems(r, true)
+		if err != nil {
+			return nil, err
+		}
+		res, err := s.setlist(items, r)
+		if err != nil {
+			return nil, err
+		}
+		if !confirmed {
+			return nil, nil
+		}
+		var out bytes.Buffer
+		out.Write([]byte{byte(TagSetResponse), byte(TagSetResponseWithList), invoke})
+		encodelength(&out, uint(len(res)))
+		for _, rr := range res {
+			out.WriteByte(byte(rr))
+		}
+		return out.Bytes(), nil
+	case TagSetRequestWithFirstDataBlock, TagSetRequestWithListAndFirstDataBlock:
+		if s.conformance&ConformanceBlockBlockTransferWithSetOrWrite == 0 {
+			return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceNotSupported), nil
+		}
+		st := &serverset{list: t == TagSetRequestWithListAndFirstDataBlock}
+		if st.list {
+			items, err := s.readitems(r, true)
+			if err != nil {
+				return nil, err
+			}
+			st.items = items
+		} else {
+			item, err := s.readitem(r, true)
+			if err != nil {
+				return nil, err
+			}
+			st.items = []DlmsLNRequestItem{item}
+		}
+		s.set = st
+		return s.setblock(invoke, r, true)
+	case TagSetRequestWithDataBlock:
+		return s.setblock(invoke, r, false)
+	}
+	return serverexception(ExceptionStateServiceUnknown, ExceptionServiceNotSupported), nil
+}
+
+// data part of set with list, returns result per item
+func (s *dlmsserver) setlist(items []DlmsLNRequestItem, r io.Reader) ([]DlmsResultTag, error) {
+	l, _, err := decodelength(r, &s.tmpbuffer)
+	if err != nil {
+		return nil, err
+	}
+	if l != uint(len(items)) {
+		return nil, fmt.Errorf("different count of items and data")
+	}
+	data := make([]DlmsData, len(items))
+	for i := range data {
+		if data[i], _, err = decodeDataTag(r, &s.tmpbuffer); err != nil {
+			return nil, err
+		}
+	}
+	res := make([]DlmsResultTag, len(items))
+	for i := range items {
+		items[i].SetData = &data[i]
+		res[i] = s.callset(items[i])
+	}
+	return res, nil
+}
+
+func (s *dlmsserver) setblock(invoke byte, r *bytes.Reader, first bool) ([]byte, error) {
+	_, err := io.ReadFull(r, s.tmpbuffer[:5])
+	if err != nil {
+		return nil, err
+	}
+	last := s.tmpbuffer[0] != 0
+	blockno := binary.BigEndian.Uint32(s.tmpbuffer[1:])
+	l, _, err := decodelength(r, &s.tmpbuffer)
+	if err != nil {
+		return nil, err
+	}
+	if l > uint(r.Len()) {
+		return nil, fmt.Errorf("set block longer than apdu")
+	}
+
+	st := s.set
+	var res DlmsResultTag
+	switch {
+	case st == nil:
+		res = TagResultNoLongSetInProgress
+	case (first && blockno != 1) || (!first && blockno != st.blockno+1):
+		res = TagResultDataBlockNumberInvalid
+	}
+	if res != TagResultSuccess {
+		s.set = nil
+		if st != nil && st.list {
+			return s.setlistresponse(invoke, blockno, len(st.items), res, nil), nil
+		}
+		return []byte{byte(TagSetResponse), byte(TagSetResponseLastDataBlock), invoke, byte(res), byte(blockno >> 24), byte(blockno >> 16), byte(blockno >> 8), byte(blockno)}, nil
+	}
+
+	_, _ = io.CopyN(&st.data, r, int64(l))
+	st.blockno = blockno
+	if !last {
+		return []byte{byte(TagSetResponse), byte(TagSetResponseDataBlock), invoke, byte(blockno >> 24), byte(blockno >> 16), byte(blockno >> 8), byte(blockno)}, nil
+	}
+
+	s.set = nil
+	if st.list {
+		results, err := s.setlist(st.items, &st.data)
+		if err != nil {
+			s.logf("Unable to decode set data: %v", err)
+			return s.setlistresponse(invoke, blockno, len(st.items), TagResultTypeUnmatched, nil), nil
+		}
+		return s.setlistresponse(invoke, blockno, len(st.items), TagResultSuccess, results), nil
+	}
+	data, _, err := decodeDataTag(&st.data, &s.tmpbuffer)
+	if err != nil {
+		s.logf("Unable to decode set data: %v", err)
+		res = TagResultTypeUnmatched
+	} else {
+		st.items[0].SetData = &data
+		res = s.callset(st.items[0])
+	}
+	return []byte{byte(TagSetResponse), byte(TagSetResponseLastDataBlock), invoke, byte(res), byte(blockno >> 24), byte(blockno >> 16), byte(blockno >> 8), byte(blockno)}, nil
+}
+
+// results are either given or the same res for every item
+func (s *dlmsserver) setlistresponse(invoke byte, blockno uint32, count int, res DlmsResultTag, results []DlmsResultTag) []byte {
+	var out bytes.Buffer
+	out.Write([]byte{byte(TagSetResponse), byte(TagSetResponseLastDataBlockWithList), invoke})
+	encodelength(&out, uint(count))
+	for i := 0; i < count; i++ {
+		if results != nil {
+			out.WriteByte(byte(results[i]))
+		} else {
+			out.WriteByte(byte(res))
+		}
+	}
+	out.Write([]byte{byte(blockno >> 24), byte(blockno >> 16), byte(blockno >> 8), byte(blockno)})
+	return out.Bytes()
+}
+
+func (s *dlmsserver) action(t actionRequestTag, invoke byte, r *bytes.Reader) ([]byte, error) {
+	if s.conformance&ConformanceBlockAction == 0 {
+		return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceNotSupported), nil
+	}
+	switch t {
+	case TagActionRequestNormal:
+		s.blocks = nil
+		item, err := s.readitem(r, false)
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.ReadFull(r, s.tmpbuffer[:1])
+		if err != nil {
+			return nil, err
+		}
+		if s.tmpbuffer[0] != 0 {
+			param, _, err := decodeDataTag(r, &s.tmpbuffer)
+			if err != nil {
+				return nil, err
+			}
+			item.SetData = &param
+		}
+		var data *DlmsData
+		res := TagResultReadWriteDenied
+		if s.handler.Action != nil {
+			data, res = s.handler.Action(item)
+		}
+		if invoke&0x40 == 0 {
+			return nil, nil
+		}
+
+		out := []byte{byte(TagActionResponse), byte(TagActionResponseNormal), invoke, byte(res)}
+		if res != TagResultSuccess || data == nil {
+			return append(out, 0), nil // no return parameters
+		}
+		var raw bytes.Buffer
+		if err = encodeData(&raw, data); err != nil {
+			s.logf("Unable to encode action data: %v", err)
+			return append(out, 1, 1, byte(TagResultOtherReason)), nil
+		}
+		if len(out)+2+raw.Len() > s.maxsend {
+			return s.startblocks(TagActionResponse, invoke, raw.Bytes())
+		}
+		out = append(out, 1, 0) // return parameters with data
+		return append(out, raw.Bytes()...), nil
+	case TagActionRequestNextPBlock:
+		_, err := io.ReadFull(r, s.tmpbuffer[:4])
+		if err != nil {
+			return nil, err
+		}
+		blockno := binary.BigEndian.Uint32(s.tmpbuffer[:])
+		if s.blocks == nil || s.blocks.tag != TagActionResponse || s.blocks.blockno != blockno {
+			s.blocks = nil
+			return serverexception(ExceptionStateServiceNotAllowed, ExceptionServiceOperationNotPossible), nil
+		}
+		return s.nextblock(invoke), nil
+	}
+	return serverexception(ExceptionStateServiceUnknown, ExceptionServiceNotSupported), nil
+}
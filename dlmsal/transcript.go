@@ -0,0 +1,82 @@
+package dlmsal
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type transcriptrecord struct {
+	Time     time.Time `json:"time"`
+	Dir      string    `json:"dir"`                // tx or rx
+	Apdu     string    `json:"apdu"`               // plain apdu in hex
+	Ciphered string    `json:"ciphered,omitempty"` // the same apdu as it went over the wire in case it was ciphered
+}
+
+// responses are streamed, so they are collected while being read and written out when the next request goes or transcript stops
+type transcript struct {
+	enc      *json.Encoder
+	pending  bool
+	rxtime   time.Time
+	raw      bytes.Buffer
+	plain    bytes.Buffer
+	ciphered bool
+}
+
+// records every apdu (including aarq/aare and rlrq/rlre) as json lines with time, direction, plain and ciphered form,
+// meant for attaching to interop bug reports, write errors are ignored
+func (d *dlmsal) StartTranscript(w io.Writer) {
+	d.StopTranscript()
+	d.transcript = &transcript{enc: json.NewEncoder(w)}
+}
+
+func (d *dlmsal) StopTranscript() {
+	if d.transcript != nil {
+		d.transcript.flush()
+		d.transcript = nil
+	}
+}
+
+func (t *transcript) write(dir string, tm time.Time, plain []byte, ciphered []byte) {
+	r := transcriptrecord{Time: tm, Dir: dir, Apdu: hex.EncodeToString(plain)}
+	if ciphered != nil {
+		r.Ciphered = hex.EncodeToString(ciphered)
+	}
+	_ = t.enc.Encode(&r)
+}
+
+func (t *transcript) flush() {
+	if !t.pending {
+		return
+	}
+	t.pending = false
+	var c []byte
+	if t.ciphered {
+		c = t.raw.Bytes()
+	}
+	t.write("rx", t.rxtime, t.plain.Bytes(), c)
+}
+
+// ciphered is nil for plain apdu
+func (t *transcript) tx(plain []byte, ciphered []byte) {
+	t.flush()
+	t.write("tx", time.Now(), plain, ciphered)
+}
+
+// whole received apdu, no streaming (aare, rlre)
+func (t *transcript) rx(plain []byte) {
+	t.flush()
+	t.write("rx", time.Now(), plain, nil)
+}
+
+func (t *transcript) rxstart(tag byte) {
+	t.flush()
+	t.pending = true
+	t.ciphered = false
+	t.rxtime = time.Now()
+	t.raw.Reset()
+	t.plain.Reset()
+	t.raw.WriteByte(tag)
+}
@@ -55,6 +55,9 @@ func (n *Value) ToString() string {
 	return "invalid" // questionable
 }
 
+// value of TagEnum, decoded enums always have this type, encoding accepts also plain integers
+type DlmsEnum uint8
+
 type DlmsDateTime struct {
 	Date      DlmsDate
 	Time      DlmsTime
@@ -143,6 +146,59 @@ type DlmsTime struct {
 	Hundredths byte
 }
 
+// midnight of the date in given location (nil means UTC), day 0xfe means last and 0xfd second last day of month,
+// other wildcards (year, month, day, dst begin/end) cant be converted to a single date
+func (d *DlmsDate) ToTime(loc *time.Location) (tt time.Time, err error) {
+	if d.Year == 0xffff {
+		return tt, fmt.Errorf("wildcard year")
+	}
+	if d.Month < 1 || d.Month > 12 {
+		return tt, fmt.Errorf("wildcard or invalid month %d", d.Month)
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	switch {
+	case d.Day == 0xfe, d.Day == 0xfd:
+		last := time.Date(int(d.Year), time.Month(d.Month)+1, 0, 0, 0, 0, 0, loc) // zeroth day of the next month
+		if d.Day == 0xfd {
+			last = last.AddDate(0, 0, -1)
+		}
+		return last, nil
+	case d.Day < 1 || d.Day > 31:
+		return tt, fmt.Errorf("wildcard or invalid day %d", d.Day)
+	}
+	tt = time.Date(int(d.Year), time.Month(d.Month), int(d.Day), 0, 0, 0, 0, loc)
+	if tt.Day() != int(d.Day) {
+		return time.Time{}, fmt.Errorf("invalid date %d-%d-%d", d.Year, d.Month, d.Day)
+	}
+	return
+}
+
+// time since midnight, wildcard second or hundredths are taken as zero, wildcard hour or minute is an error
+func (t *DlmsTime) ToDuration() (time.Duration, error) {
+	if t.Hour > 23 {
+		return 0, fmt.Errorf("wildcard or invalid hour %d", t.Hour)
+	}
+	if t.Minute > 59 {
+		return 0, fmt.Errorf("wildcard or invalid minute %d", t.Minute)
+	}
+	d := time.Duration(t.Hour)*time.Hour + time.Duration(t.Minute)*time.Minute
+	if t.Second != 0xff {
+		if t.Second > 59 {
+			return 0, fmt.Errorf("invalid second %d", t.Second)
+		}
+		d += time.Duration(t.Second) * time.Second
+	}
+	if t.Hundredths != 0xff {
+		if t.Hundredths > 99 {
+			return 0, fmt.Errorf("invalid hundredths %d", t.Hundredths)
+		}
+		d += time.Duration(t.Hundredths) * 10 * time.Millisecond
+	}
+	return d, nil
+}
+
 type DlmsObis struct {
 	A byte
 	B byte
@@ -156,6 +212,20 @@ func (o *DlmsObis) String() string {
 	return fmt.Sprintf("%d-%d:%d.%d.%d.%d", o.A, o.B, o.C, o.D, o.E, o.F)
 }
 
+// text form is the same as String, so obis can be used directly in json/yaml configs
+func (o DlmsObis) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *DlmsObis) UnmarshalText(text []byte) error {
+	ob, err := NewDlmsObisFromString(string(text))
+	if err != nil {
+		return fmt.Errorf("invalid obis %q: %w", text, err)
+	}
+	*o = ob
+	return nil
+}
+
 func (o *DlmsObis) Bytes() []byte {
 	return []byte{o.A, o.B, o.C, o.D, o.E, o.F}
 }
@@ -40,6 +40,16 @@ func EncodeSimpleRangeAccess(from *DlmsDateTime, to *DlmsDateTime) DlmsData {
 	return DlmsData{Tag: TagStructure, Value: ch}
 }
 
+// entry descriptor (selector 2), entries are numbered from 1, zero to_entry/to_selected_value means till the end
+func EncodeEntryAccess(fromEntry uint32, toEntry uint32, fromValue uint16, toValue uint16) DlmsData {
+	ch := make([]DlmsData, 4)
+	ch[0] = DlmsData{Tag: TagDoubleLongUnsigned, Value: fromEntry}
+	ch[1] = DlmsData{Tag: TagDoubleLongUnsigned, Value: toEntry}
+	ch[2] = DlmsData{Tag: TagLongUnsigned, Value: fromValue}
+	ch[3] = DlmsData{Tag: TagLongUnsigned, Value: toValue}
+	return DlmsData{Tag: TagStructure, Value: ch}
+}
+
 func encodelength(dst *bytes.Buffer, len uint) {
 	if len < 128 {
 		dst.WriteByte(byte(len))
@@ -150,6 +160,28 @@ func decodelength(src io.Reader, tmp *tmpbuffer) (uint, int, error) {
 	return r, c + 1, nil
 }
 
+// upper bound of allocations based on length read from the wire, bigger things grow as the data really come,
+// so corrupted or malicious length cant allocate gigabytes from a few bytes
+const maxPrealloc = 4096
+
+func readbytes(src io.Reader, l uint) ([]byte, error) {
+	if l <= maxPrealloc {
+		v := make([]byte, l)
+		_, err := io.ReadFull(src, v)
+		return v, err
+	}
+	var b bytes.Buffer
+	b.Grow(maxPrealloc)
+	n, err := b.ReadFrom(io.LimitReader(src, int64(l)))
+	if err != nil {
+		return nil, err
+	}
+	if uint(n) != l {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return b.Bytes(), nil
+}
+
 func decodetag(src []byte, tmp *tmpbuffer) (byte, int, []byte, error) {
 	if len(src) < 2 {
 		return 0, 0, nil, fmt.Errorf("no data available")
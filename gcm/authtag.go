@@ -0,0 +1,30 @@
+package gcm
+
+import "fmt"
+
+func (g *gcm) AuthTag(sc byte, fc uint32, systitle []byte, challenge []byte) ([]byte, error) {
+	if len(systitle) != 8 {
+		return nil, fmt.Errorf("systitle has to be 8 bytes long")
+	}
+	if sc&0xf0 != 0x10 {
+		return nil, fmt.Errorf("authentication tag needs authentication only security control byte, got %v", sc)
+	}
+	iv := g.tmp[:AES_BLOCK_SIZE]
+	copy(iv, systitle)
+	iv[8] = byte(fc >> 24)
+	iv[9] = byte(fc >> 16)
+	iv[10] = byte(fc >> 8)
+	iv[11] = byte(fc)
+	iv[12] = 0
+	iv[13] = 0
+	iv[14] = 0
+	iv[15] = 1
+
+	aad := make([]byte, 1+len(g.ak)+len(challenge))
+	aad[0] = sc
+	copy(aad[1:], g.ak)
+	copy(aad[1+len(g.ak):], challenge)
+	tag := make([]byte, GCM_TAG_LENGTH)
+	g.aes_gcm_ae(nil, aad, nil, tag)
+	return tag, nil
+}
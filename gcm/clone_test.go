@@ -0,0 +1,58 @@
+package gcm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+// clones share only read only state, run with -race
+func TestCloneConcurrent(t *testing.T) {
+	ek := bytes.Repeat([]byte{0x11}, 16)
+	ak := bytes.Repeat([]byte{0x22}, 16)
+	g, err := NewGCM(ek, ak)
+	if err != nil {
+		t.Fatal(err)
+	}
+	systitle := []byte("ABCDEFGH")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for n := 0; n < 2; n++ {
+		c := g.Clone()
+		wg.Add(1)
+		go func(c Gcm, n int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				plain := bytes.Repeat([]byte{byte(n), byte(i)}, 50+i)
+				fc := uint32(n*1000 + i)
+				enc, err := c.Encrypt(nil, 0x30, fc, systitle, plain)
+				if err != nil {
+					errs <- err
+					return
+				}
+				s, err := c.GetDecryptorStream(0x30, fc, systitle, bytes.NewReader(enc))
+				if err != nil {
+					errs <- err
+					return
+				}
+				dec, err := io.ReadAll(s)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if !bytes.Equal(dec, plain) {
+					errs <- fmt.Errorf("clone %d iteration %d: plaintext mismatch", n, i)
+					return
+				}
+			}
+		}(c, n)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,5 @@
+package gcm
+
+import "errors"
+
+var ErrReplayedFrameCounter = errors.New("replayed frame counter")
@@ -15,7 +15,13 @@ const (
 	GCM_TAG_LENGTH     = 12
 )
 
+// Gcm instance is not reentrant, all calls share the same scratch buffers (tmp, aadbuf) and also
+// decryptor streams use them until they are fully read. So one instance per session/goroutine,
+// use Clone to get an independent one with the same keys.
+// System title is a parameter of every call (only iv is built from it), so one instance can serve
+// more meters/clients with the same keys without rebuilding ghash tables, just not concurrently.
 type Gcm interface { // add length to the streamer interface? add systitle to constructor? not to copy it every damn time
+	Clone() Gcm
 	GetEncryptLength(scControl byte, apdu []byte) (int, error)
 	// ret can be nil in case of not reused, ret and apdu can overlap, but exactly
 	Encrypt(ret []byte, sc byte, fc uint32, systitle []byte, apdu []byte) ([]byte, error)
@@ -27,6 +33,19 @@ type Gcm interface { // add length to the streamer interface? add systitle to co
 	Decrypt2(ret []byte, scControl byte, scContent byte, fc uint32, systitle []byte, apdu []byte) ([]byte, error)
 	GetDecryptorStream(sc byte, fc uint32, systitle []byte, apdu io.Reader) (GcmDecryptorStream, error)
 	GetDecryptorStream2(scControl byte, scContent byte, fc uint32, systitle []byte, apdu io.Reader) (GcmDecryptorStream, error)
+	// gmac check of authenticated only apdu without buffering it, plaintext goes to dst (can be nil) before the tag is checked at the end
+	VerifyStream(sc byte, fc uint32, systitle []byte, apdu io.Reader, dst io.Writer) error
+	// opt-in check of received frame counters, decryption of already seen or too old frame counter fails with ErrReplayedFrameCounter,
+	// counters are tracked per instance and not per system title, so dont enable it on an instance shared between meters
+	SetReplayProtection(on bool, window uint32)
+	// whole general-glo-ciphering apdu (push, notification), header is parsed here so the caller needs only the keys
+	DecryptGeneralGlo(apdu []byte) (plaintext []byte, fc uint32, senderTitle []byte, err error)
+	// 12 bytes gmac over the challenge (sc || ak || challenge as aad) as used by hls gmac authentication, f(StoC) with own
+	// system title for the client side, f(CtoS) with meter system title to check the meter, sc has to be authentication only
+	AuthTag(sc byte, fc uint32, systitle []byte, challenge []byte) ([]byte, error)
+	// replaces AK in place, ghash tables depend only on EK so nothing else changes, nil removes AK,
+	// not safe while any operation or decryptor stream of this instance is in progress, clones keep the old AK
+	SetAuthenticationKey(ak []byte) error
 }
 
 type gcm struct {
@@ -37,6 +56,7 @@ type gcm struct {
 	aes    cipher.Block
 	aad    []byte
 	aadbuf [1 + 32]byte
+	replay replaystate
 }
 
 // no constant arrays in go, but these numbers are black magic
@@ -63,6 +83,30 @@ func NewGCM(ek []byte, ak []byte) (Gcm, error) {
 	return &g, nil
 }
 
+func (g *gcm) SetAuthenticationKey(ak []byte) error {
+	if ak != nil && len(ak) != 16 && len(ak) != 24 && len(ak) != 32 {
+		return fmt.Errorf("AK has to be 16, 24 or 32 bytes long")
+	}
+	clear(g.aadbuf[1:]) // dont keep the old key around
+	copy(g.aadbuf[1:], ak)
+	g.aad = g.aadbuf[:1+len(ak)]
+	g.ak = g.aadbuf[1 : 1+len(ak)]
+	return nil
+}
+
+func (g *gcm) Clone() Gcm {
+	n := gcm{
+		hl:     g.hl, // tables and cipher are read only after construction
+		hh:     g.hh,
+		aes:    g.aes,
+		aadbuf: g.aadbuf,
+		replay: g.replay,
+	}
+	n.aad = n.aadbuf[:len(g.aad)]
+	n.ak = n.aadbuf[1 : 1+len(g.ak)]
+	return &n
+}
+
 // using first tmp slot, depends on zero initialized arrays
 func (g *gcm) make_tables() {
 	h := g.tmp[:AES_BLOCK_SIZE]
@@ -97,6 +141,17 @@ func (g *gcm) Decrypt(ret []byte, sc byte, fc uint32, systitle []byte, apdu []by
 }
 
 func (g *gcm) Decrypt2(ret []byte, scControl byte, scContent byte, fc uint32, systitle []byte, apdu []byte) ([]byte, error) {
+	if err := g.checkfc(fc); err != nil {
+		return nil, err
+	}
+	ret, err := g.decrypt2(ret, scControl, scContent, fc, systitle, apdu)
+	if err == nil {
+		g.commitfc(fc)
+	}
+	return ret, err
+}
+
+func (g *gcm) decrypt2(ret []byte, scControl byte, scContent byte, fc uint32, systitle []byte, apdu []byte) ([]byte, error) {
 	if len(systitle) != 8 {
 		return nil, fmt.Errorf("systitle has to be 8 bytes long")
 	}
@@ -179,6 +234,10 @@ func (g *gcm) GetDecryptorStream2(scControl byte, scContent byte, fc uint32, sys
 	if apdu == nil {
 		return nil, fmt.Errorf("apdu is nil")
 	}
+	// stream tag is verified at the very end, frame counter is committed by the stream only then
+	if err := g.checkfc(fc); err != nil {
+		return nil, err
+	}
 	iv := g.tmp[:AES_BLOCK_SIZE] // a bit hardcore
 	copy(iv, systitle)
 	iv[8] = byte(fc >> 24)
@@ -192,11 +251,11 @@ func (g *gcm) GetDecryptorStream2(scControl byte, scContent byte, fc uint32, sys
 
 	switch scControl & 0xf0 {
 	case 0x10:
-		return newgcmdecstream10(g, scContent, apdu), nil
+		return newgcmdecstream10(g, scContent, fc, apdu), nil
 	case 0x20:
-		return newgcmdecstream20(g, apdu), nil
+		return newgcmdecstream20(g, fc, apdu), nil
 	case 0x30:
-		return newgcmdecstream30(g, scContent, apdu), nil
+		return newgcmdecstream30(g, scContent, fc, apdu), nil
 	}
 	return nil, fmt.Errorf("unsupported security control byte: %v", scControl)
 }
@@ -26,10 +26,11 @@ type gcmdecstream10 struct { // no tag/hash, no aad, decrypt only
 	J0          []byte
 	S           []byte
 	ineof       bool
+	fc          uint32 // committed to replay state once the tag matches
 }
 
-func newgcmdecstream10(master *gcm, sc byte, src io.Reader) GcmDecryptorStream {
-	ret := gcmdecstream10{master: master, apdu: src, blockoffset: 0, blockread: 0, blockoffer: 0, ineof: false}
+func newgcmdecstream10(master *gcm, sc byte, fc uint32, src io.Reader) GcmDecryptorStream {
+	ret := gcmdecstream10{master: master, apdu: src, blockoffset: 0, blockread: 0, blockoffer: 0, ineof: false, fc: fc}
 	ret.J0 = master.tmp[:AES_BLOCK_SIZE] // yes, this is reusable hardcore
 	ret.S = master.tmp[AES_BLOCK_SIZE : AES_BLOCK_SIZE<<1]
 	ret.block[0] = sc
@@ -113,6 +114,7 @@ func (g *gcmdecstream10) Read(p []byte) (n int, err error) {
 		if !bytes.Equal(g.S[:GCM_TAG_LENGTH], g.block[n-GCM_TAG_LENGTH:n]) {
 			return 0, fmt.Errorf("tag mismatch")
 		}
+		m.commitfc(g.fc)
 		g.blockoffer = n - GCM_TAG_LENGTH
 	} else {
 		bl := (len(g.block) >> AES_BLOCK_SIZE_ROT) - 1 // keep last block in the buffer till some eof here, always full block read
@@ -138,10 +140,11 @@ type gcmdecstream20 struct { // no tag/hash, no aad, decrypt only
 	blockoffset int
 	J0          []byte
 	ineof       bool
+	fc          uint32 // committed to replay state at the end, there is no tag to wait for
 }
 
-func newgcmdecstream20(master *gcm, src io.Reader) GcmDecryptorStream {
-	ret := gcmdecstream20{master: master, apdu: src, blockoffset: 0, blockread: 0, ineof: false}
+func newgcmdecstream20(master *gcm, fc uint32, src io.Reader) GcmDecryptorStream {
+	ret := gcmdecstream20{master: master, apdu: src, blockoffset: 0, blockread: 0, ineof: false, fc: fc}
 	ret.J0 = master.tmp[:AES_BLOCK_SIZE] // yes, this is reusable hardcore
 	inc32(ret.J0)
 	return &ret
@@ -168,6 +171,7 @@ func (g *gcmdecstream20) Read(p []byte) (n int, err error) {
 	if err != nil {
 		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
 			g.ineof = true
+			g.master.commitfc(g.fc)
 			if g.blockread == 0 {
 				return 0, io.EOF
 			}
@@ -196,10 +200,11 @@ type gcmdecstream30 struct { // no tag/hash, no aad, decrypt only
 	J0          []byte
 	S           []byte
 	ineof       bool
+	fc          uint32 // committed to replay state once the tag matches
 }
 
-func newgcmdecstream30(master *gcm, sc byte, src io.Reader) GcmDecryptorStream {
-	ret := gcmdecstream30{master: master, apdu: src, blockoffset: 0, blockread: 0, cryptsize: 0, ineof: false}
+func newgcmdecstream30(master *gcm, sc byte, fc uint32, src io.Reader) GcmDecryptorStream {
+	ret := gcmdecstream30{master: master, apdu: src, blockoffset: 0, blockread: 0, cryptsize: 0, ineof: false, fc: fc}
 	ret.J0 = master.tmp[:AES_BLOCK_SIZE] // yes, this is reusable hardcore
 	inc32(ret.J0)
 	ret.S = master.tmp[AES_BLOCK_SIZE : AES_BLOCK_SIZE<<1]
@@ -273,6 +278,7 @@ func (g *gcmdecstream30) Read(p []byte) (n int, err error) {
 		if !bytes.Equal(g.S[:GCM_TAG_LENGTH], g.block[n-GCM_TAG_LENGTH:n]) {
 			return 0, fmt.Errorf("tag mismatch")
 		}
+		m.commitfc(g.fc)
 		g.blockoffer = n - GCM_TAG_LENGTH
 	} else {
 		bl := (len(g.block) >> AES_BLOCK_SIZE_ROT) - 1 // keep last block in the buffer till some eof here, always full block read
@@ -0,0 +1,64 @@
+package gcm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+const tagGeneralGloCiphering = 219
+
+// parses general-glo-ciphering apdu including its tag (system title, sc, fc) and decrypts the content, so pushes can be decrypted without dlmsal,
+// returned system title is a copy
+func (g *gcm) DecryptGeneralGlo(apdu []byte) (plaintext []byte, fc uint32, senderTitle []byte, err error) {
+	if len(apdu) < 1 || apdu[0] != tagGeneralGloCiphering {
+		return nil, 0, nil, fmt.Errorf("not a general-glo-ciphering apdu")
+	}
+	l, c, err := decodelength(apdu[1:])
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	apdu = apdu[1+c:]
+	if uint(len(apdu)) < l {
+		return nil, 0, nil, fmt.Errorf("too short apdu for system title")
+	}
+	senderTitle = make([]byte, l)
+	copy(senderTitle, apdu[:l])
+	apdu = apdu[l:]
+
+	l, c, err = decodelength(apdu)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	apdu = apdu[c:]
+	if uint(len(apdu)) != l {
+		return nil, 0, nil, fmt.Errorf("ciphered content length mismatch, expected %d, got %d", l, len(apdu))
+	}
+	if l < 5 {
+		return nil, 0, nil, fmt.Errorf("too short ciphered content, no space for sc and fc")
+	}
+	fc = binary.BigEndian.Uint32(apdu[1:])
+	plaintext, err = g.Decrypt(nil, apdu[0], fc, senderTitle, apdu[5:])
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	return plaintext, fc, senderTitle, nil
+}
+
+// axdr length, returns length and number of consumed bytes
+func decodelength(src []byte) (uint, int, error) {
+	if len(src) < 1 {
+		return 0, 0, fmt.Errorf("no data for length")
+	}
+	if src[0] < 0x80 {
+		return uint(src[0]), 1, nil
+	}
+	n := int(src[0] & 0x7f)
+	if n == 0 || n > 4 || len(src) < 1+n {
+		return 0, 0, fmt.Errorf("invalid length encoding")
+	}
+	var l uint
+	for i := 1; i <= n; i++ {
+		l = l<<8 | uint(src[i])
+	}
+	return l, 1 + n, nil
+}
@@ -0,0 +1,109 @@
+package gcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// reference output of the standard library gcm in dlms layout: plaintext||tag for 0x10, ciphertext for 0x20, ciphertext||tag for 0x30
+func refencrypt(t *testing.T, ek []byte, ak []byte, sc byte, fc uint32, systitle []byte, apdu []byte) []byte {
+	t.Helper()
+	block, err := aes.NewCipher(ek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := cipher.NewGCMWithTagSize(block, GCM_TAG_LENGTH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, 12)
+	copy(nonce, systitle)
+	binary.BigEndian.PutUint32(nonce[8:], fc)
+	aad := append([]byte{sc}, ak...)
+	switch sc & 0xf0 {
+	case 0x10:
+		return ref.Seal(append([]byte{}, apdu...), nonce, nil, append(aad, apdu...))
+	case 0x20:
+		out := ref.Seal(nil, nonce, apdu, nil)
+		return out[:len(apdu)]
+	default:
+		return ref.Seal(nil, nonce, apdu, aad)
+	}
+}
+
+// the hand written path has to match crypto/cipher for every aes key size, not only aes-128
+func TestKeySizesAgainstStdlib(t *testing.T) {
+	systitle := []byte("ABCDEFGH")
+	for _, kl := range []int{16, 24, 32} {
+		ek := make([]byte, kl)
+		ak := make([]byte, kl)
+		for i := range ek {
+			ek[i] = byte(i*7 + 1)
+			ak[i] = byte(i*13 + 5)
+		}
+		g, err := NewGCM(ek, ak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sc := range []byte{0x10, 0x20, 0x30} {
+			for _, l := range []int{1, 15, 16, 17, 63, 64, 65, 100, 333} {
+				name := fmt.Sprintf("key %d sc %02x len %d", kl, sc, l)
+				apdu := make([]byte, l)
+				for i := range apdu {
+					apdu[i] = byte(i*31 + kl)
+				}
+				fc := uint32(0x01020304 + l)
+				want := refencrypt(t, ek, ak, sc, fc, systitle, apdu)
+
+				enc, err := g.Encrypt(nil, sc, fc, systitle, apdu)
+				if err != nil {
+					t.Fatalf("%s: %v", name, err)
+				}
+				if !bytes.Equal(enc, want) {
+					t.Fatalf("%s: encrypt differs from crypto/cipher", name)
+				}
+
+				dec, err := g.Decrypt(nil, sc, fc, systitle, want)
+				if err != nil {
+					t.Fatalf("%s: decrypt: %v", name, err)
+				}
+				if !bytes.Equal(dec, apdu) {
+					t.Fatalf("%s: decrypt plaintext mismatch", name)
+				}
+
+				s, err := g.GetDecryptorStream(sc, fc, systitle, bytes.NewReader(want))
+				if err != nil {
+					t.Fatalf("%s: decryptor stream: %v", name, err)
+				}
+				dec, err = io.ReadAll(s)
+				if err != nil {
+					t.Fatalf("%s: decryptor stream: %v", name, err)
+				}
+				if !bytes.Equal(dec, apdu) {
+					t.Fatalf("%s: decryptor stream plaintext mismatch", name)
+				}
+
+				if sc == 0x20 { // no tag to break
+					continue
+				}
+				bad := append([]byte{}, want...)
+				bad[len(bad)-1] ^= 1
+				if _, err = g.Decrypt(nil, sc, fc, systitle, bad); err == nil {
+					t.Fatalf("%s: decrypt accepted broken tag", name)
+				}
+				s, err = g.GetDecryptorStream(sc, fc, systitle, bytes.NewReader(bad))
+				if err != nil {
+					t.Fatalf("%s: decryptor stream: %v", name, err)
+				}
+				if _, err = io.ReadAll(s); err == nil {
+					t.Fatalf("%s: decryptor stream accepted broken tag", name)
+				}
+			}
+		}
+	}
+}
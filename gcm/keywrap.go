@@ -0,0 +1,70 @@
+package gcm
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"encoding/binary"
+	"fmt"
+)
+
+// RFC 3394 default initial value
+var keywrapiv = [8]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// AES key wrap (RFC 3394), used for global key transfer in security setup
+func WrapKey(kek []byte, key []byte) ([]byte, error) {
+	if len(key) < 16 || len(key)&7 != 0 {
+		return nil, fmt.Errorf("key has to be at least 16 bytes long and multiple of 8")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(key) >> 3
+	ret := make([]byte, len(key)+8)
+	copy(ret, keywrapiv[:])
+	copy(ret[8:], key)
+
+	var b [AES_BLOCK_SIZE]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b[:8], ret[:8])
+			copy(b[8:], ret[i<<3:(i+1)<<3])
+			block.Encrypt(b[:], b[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(ret[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(ret[i<<3:], b[8:])
+		}
+	}
+	return ret, nil
+}
+
+func UnwrapKey(kek []byte, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)&7 != 0 {
+		return nil, fmt.Errorf("wrapped key has to be at least 24 bytes long and multiple of 8")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := (len(wrapped) >> 3) - 1
+	var a [8]byte
+	copy(a[:], wrapped[:8])
+	ret := make([]byte, len(wrapped)-8)
+	copy(ret, wrapped[8:])
+
+	var b [AES_BLOCK_SIZE]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(a[:])^t)
+			copy(b[8:], ret[(i-1)<<3:i<<3])
+			block.Decrypt(b[:], b[:])
+			copy(a[:], b[:8])
+			copy(ret[(i-1)<<3:], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a[:], keywrapiv[:]) != 1 {
+		return nil, fmt.Errorf("key unwrap integrity check failed")
+	}
+	return ret, nil
+}
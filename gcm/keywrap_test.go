@@ -0,0 +1,74 @@
+package gcm
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// rfc 3394 section 4.1 - 4.6
+var keywrapvectors = []struct {
+	name    string
+	kek     string
+	key     string
+	wrapped string
+}{
+	{"4.1 128 kek 128 key", "000102030405060708090a0b0c0d0e0f", "00112233445566778899aabbccddeeff",
+		"1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5"},
+	{"4.2 192 kek 128 key", "000102030405060708090a0b0c0d0e0f1011121314151617", "00112233445566778899aabbccddeeff",
+		"96778b25ae6ca435f92b5b97c050aed2468ab8a17ad84e5d"},
+	{"4.3 256 kek 128 key", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", "00112233445566778899aabbccddeeff",
+		"64e8c3f9ce0f5ba263e9777905818a2a93c8191e7d6e8ae7"},
+	{"4.4 192 kek 192 key", "000102030405060708090a0b0c0d0e0f1011121314151617", "00112233445566778899aabbccddeeff0001020304050607",
+		"031d33264e15d33268f24ec260743edce1c6c7ddee725a936ba814915c6762d2"},
+	{"4.5 256 kek 192 key", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", "00112233445566778899aabbccddeeff0001020304050607",
+		"a8f9bc1612c68b3ff6e6f4fbe30e71e4769c8b80a32cb8958cd5d17d6b254da1"},
+	{"4.6 256 kek 256 key", "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f", "00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f",
+		"28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21"},
+}
+
+func TestKeyWrapRFC3394(t *testing.T) {
+	for _, v := range keywrapvectors {
+		t.Run(v.name, func(t *testing.T) {
+			kek, key, wrapped := unhex(t, v.kek), unhex(t, v.key), unhex(t, v.wrapped)
+			w, err := WrapKey(kek, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(w, wrapped) {
+				t.Fatalf("wrap mismatch, got %x", w)
+			}
+			u, err := UnwrapKey(kek, wrapped)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(u, key) {
+				t.Fatalf("unwrap mismatch, got %x", u)
+			}
+		})
+	}
+}
+
+func TestKeyUnwrapTampered(t *testing.T) {
+	v := keywrapvectors[0]
+	kek, wrapped := unhex(t, v.kek), unhex(t, v.wrapped)
+	for i := range wrapped {
+		w := bytes.Clone(wrapped)
+		w[i] ^= 0x01
+		if _, err := UnwrapKey(kek, w); err == nil {
+			t.Fatalf("tampered byte %d unwrapped without error", i)
+		}
+	}
+	if _, err := UnwrapKey(unhex(t, keywrapvectors[1].kek), wrapped); err == nil {
+		t.Fatal("unwrap with wrong kek succeeded")
+	}
+}
@@ -0,0 +1,57 @@
+package gcm
+
+const maxReplayWindow = 64
+
+// receive side frame counter tracking, off by default as gcm can be used for both directions or stateless
+type replaystate struct {
+	on      bool
+	window  uint32
+	started bool
+	highest uint32
+	seen    uint64 // bit i means highest-i was already received
+}
+
+// window 0 means strictly increasing frame counters, otherwise that many older (not yet seen) counters are accepted (max 64)
+func (g *gcm) SetReplayProtection(on bool, window uint32) {
+	if window > maxReplayWindow {
+		window = maxReplayWindow
+	}
+	g.replay = replaystate{on: on, window: window}
+}
+
+func (g *gcm) checkfc(fc uint32) error {
+	r := &g.replay
+	if !r.on || !r.started || fc > r.highest {
+		return nil
+	}
+	diff := r.highest - fc
+	if diff >= r.window || diff >= maxReplayWindow || r.seen&(1<<diff) != 0 {
+		return ErrReplayedFrameCounter
+	}
+	return nil
+}
+
+func (g *gcm) commitfc(fc uint32) {
+	r := &g.replay
+	if !r.on {
+		return
+	}
+	if !r.started {
+		r.started = true
+		r.highest = fc
+		r.seen = 1
+		return
+	}
+	if fc > r.highest {
+		shift := fc - r.highest
+		if shift >= maxReplayWindow {
+			r.seen = 0
+		} else {
+			r.seen <<= shift
+		}
+		r.seen |= 1
+		r.highest = fc
+		return
+	}
+	r.seen |= 1 << (r.highest - fc)
+}
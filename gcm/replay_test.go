@@ -0,0 +1,55 @@
+package gcm
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+// forged frame must not move the counter, only a stream with matching tag does
+func TestReplayForgedStream(t *testing.T) {
+	ek := bytes.Repeat([]byte{0x11}, 16)
+	ak := bytes.Repeat([]byte{0x22}, 16)
+	g, err := NewGCM(ek, ak)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.SetReplayProtection(true, 0)
+	systitle := []byte("ABCDEFGH")
+	plain := []byte("some response apdu")
+
+	for _, sc := range []byte{0x10, 0x30} {
+		forged, err := g.Encrypt(nil, sc, 0xffffffff, systitle, plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		forged[len(forged)-1] ^= 1
+		s, err := g.GetDecryptorStream(sc, 0xffffffff, systitle, bytes.NewReader(forged))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = io.ReadAll(s); err == nil {
+			t.Fatalf("sc %02x: forged tag accepted", sc)
+		}
+	}
+
+	enc, err := g.Encrypt(nil, 0x30, 5, systitle, plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := g.GetDecryptorStream(0x30, 5, systitle, bytes.NewReader(enc))
+	if err != nil {
+		t.Fatalf("genuine frame rejected after forged one: %v", err)
+	}
+	dec, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(dec, plain) {
+		t.Fatal("plaintext mismatch")
+	}
+	if _, err = g.GetDecryptorStream(0x30, 5, systitle, bytes.NewReader(enc)); !errors.Is(err, ErrReplayedFrameCounter) {
+		t.Fatalf("replayed frame counter not detected: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package gcm
+
+import "fmt"
+
+// tries candidate instances (e.g. old and new key during rekeying) and returns plaintext and index of the one whose tag matched,
+// only security control with authentication makes sense here, without the tag there is no way to tell which key is right,
+// ret must not overlap apdu as failed attempts can write into it
+func DecryptAny(candidates []Gcm, ret []byte, sc byte, fc uint32, systitle []byte, apdu []byte) ([]byte, int, error) {
+	if sc&0x10 == 0 {
+		return nil, -1, fmt.Errorf("security control %02x has no authentication tag, unable to choose key", sc)
+	}
+	if len(candidates) == 0 {
+		return nil, -1, fmt.Errorf("no candidate keys")
+	}
+	var err error
+	for i, g := range candidates {
+		var r []byte
+		r, err = g.Decrypt(ret, sc, fc, systitle, apdu)
+		if err == nil {
+			return r, i, nil
+		}
+	}
+	return nil, -1, fmt.Errorf("no candidate key matched: %w", err)
+}
@@ -0,0 +1,23 @@
+package gcm
+
+import (
+	"fmt"
+	"io"
+)
+
+// streaming gmac check of authenticated only (sc 0x10) apdu, plaintext followed by tag is read from apdu and passed to dst (can be nil) as it goes,
+// whole payload is never held in memory, so dst gets unverified data and the caller has to throw them away in case of error
+func (g *gcm) VerifyStream(sc byte, fc uint32, systitle []byte, apdu io.Reader, dst io.Writer) error {
+	if sc&0xf0 != 0x10 {
+		return fmt.Errorf("security control %02x is not authentication only", sc)
+	}
+	str, err := g.GetDecryptorStream(sc, fc, systitle, apdu)
+	if err != nil {
+		return err
+	}
+	if dst == nil {
+		dst = io.Discard
+	}
+	_, err = io.Copy(dst, str)
+	return err
+}
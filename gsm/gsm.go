@@ -1,6 +1,7 @@
 package gsm
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"regexp"
@@ -148,6 +149,16 @@ func (g *gsm) GetRxTxBytes() (int64, int64) {
 	return g.transport.GetRxTxBytes()
 }
 
+func (g *gsm) OverheadBytes() int {
+	return base.OverheadBytes(g.transport)
+}
+
+func (g *gsm) ConnectionInfo() map[string]string {
+	ret := base.ConnectionInfo(g.transport)
+	ret["gsm.number"] = g.number
+	return ret
+}
+
 func (g *gsm) sendCommand(cmd GsmCommand) error {
 	g.logf("send cmd: %s", cmd.Command)
 	atb := append([]byte(cmd.Command), cr)
@@ -241,6 +252,11 @@ func (g *gsm) startInit() error {
 	return fmt.Errorf("modem not responding")
 }
 
+// OpenContext implements base.Stream.
+func (g *gsm) OpenContext(ctx context.Context) error {
+	return base.OpenLayer(ctx, g.transport, g.Open)
+}
+
 // Open implements base.Stream.
 func (g *gsm) Open() error {
 	if g.isopen { // a bit controversal
@@ -313,6 +329,10 @@ func (g *gsm) SetMaxReceivedBytes(m int64) {
 	g.transport.SetMaxReceivedBytes(m)
 }
 
+func (g *gsm) RedactNextWrite(r []base.Redaction) {
+	base.RedactNextWrite(g.transport, r)
+}
+
 // Write implements base.Stream.
 func (g *gsm) Write(src []byte) error {
 	if !g.isconnected {
@@ -0,0 +1,5 @@
+package hdlc
+
+import "errors"
+
+var ErrSegmentTooLarge = errors.New("segmented response exceeds reassembly limit")
@@ -1,9 +1,11 @@
 package hdlc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/cybroslabs/libdlms-go/base"
@@ -19,6 +21,8 @@ const (
 	maxRRframecycles = 10
 	maxEmptycycles   = 10
 	maxReadoutBytes  = 1000000
+	allstation       = 0x7f   // single byte all-station address
+	allstation2      = 0x3fff // two byte all-station address
 )
 
 type maclayer struct {
@@ -37,6 +41,14 @@ type maclayer struct {
 	tobereadpacket *macpacket
 	emptyframes    int
 	addrlen        int
+	stats          Stats
+	segmentbytes   int  // received info bytes of the current response
+	draining       bool // dropping rest of too large response
+	flagonline     bool // our closing flag was the last thing sent and nothing was received since
+
+	redactnext  []base.Redaction // of the next write
+	redactframe []base.Redaction // of info bytes in sendbuffer
+	lastredact  []base.Redaction // of lastsend
 
 	settings Settings
 }
@@ -56,9 +68,22 @@ type Settings struct {
 	DontNegotiate   bool
 	SnrmRetransmits int
 	Retransmits     int
+	MaxResponseSize int // reassembly limit of a single (segmented) response, zero means no limit
+
+	// vendor specific parameters appended to the snrm parameter group and info field of disc frame, usually empty
+	ExtraSnrmInfo []byte
+	DiscInfo      []byte
+
+	// single 0x7e doubles as closing flag of one frame and opening flag of the next one for back to back frames (sent without
+	// anything received in between), default is every frame with both own flags as always, set it for meters wanting shared flags
+	DoubleFlag bool
+
+	// frames with all-station address (0x7f, 0x3fff for two byte addresses) are accepted besides configured ones,
+	// needed for receiving broadcasted clock sync and similar
+	AcceptBroadcast bool
 }
 
-func New(transport base.Stream, settings *Settings) (base.Stream, error) {
+func New(transport base.Stream, settings *Settings) (HdlcStream, error) {
 	if settings.Logical > 0x3fff {
 		return nil, fmt.Errorf("invalid logical address")
 	}
@@ -68,6 +93,9 @@ func New(transport base.Stream, settings *Settings) (base.Stream, error) {
 	if settings.Client > 0x7f {
 		return nil, fmt.Errorf("invalid client address")
 	}
+	if len(settings.ExtraSnrmInfo)+23 > 128 || len(settings.DiscInfo) > 128 { // snrm goes before negotiation, so default info size, the same limit for disc fits any link
+		return nil, fmt.Errorf("snrm or disc info too long")
+	}
 	if settings.MaxRcv > initpacketlength {
 		settings.MaxRcv = initpacketlength
 	} else if settings.MaxRcv < 128 {
@@ -141,7 +169,7 @@ func (w *maclayer) Close() error {
 	}
 
 	// send even disconnect
-	err = w.writepacket(macpacket{control: 0x43, info: nil, segmented: false}, true)
+	err = w.writepacket(macpacket{control: 0x43, info: w.settings.DiscInfo, segmented: false}, true)
 	if err != nil {
 		return fmt.Errorf("unable to create disconnect packet")
 	}
@@ -155,6 +183,9 @@ func (w *maclayer) Close() error {
 }
 
 func (w *maclayer) retransmit() error {
+	w.stats.Retransmits++
+	w.flagonline = true
+	base.RedactNextWrite(w.transport, w.lastredact)
 	return w.transport.Write(w.lastsend)
 }
 
@@ -162,6 +193,7 @@ func (w *maclayer) Open() error {
 	if w.isopen {
 		return nil
 	}
+	w.flagonline = false
 	if err := w.transport.Open(); err != nil {
 		return err
 	}
@@ -179,6 +211,8 @@ func (w *maclayer) Open() error {
 			p = append(p, 0x81, 0x80, 0x14, 0x05, 0x01, byte(w.settings.MaxSnd), 0x06, 0x01, byte(w.settings.MaxRcv))
 		}
 		p = append(p, 0x07, 0x04, 0x00, 0x00, 0x00, 0x01, 0x08, 0x04, 0x00, 0x00, 0x00, 0x01)
+		p = append(p, w.settings.ExtraSnrmInfo...)
+		p[2] = byte(len(p) - 3) // group length
 	}
 
 	var r []macpacket
@@ -227,6 +261,36 @@ func (w *maclayer) Open() error {
 	return nil
 }
 
+func (w *maclayer) OpenContext(ctx context.Context) error {
+	return base.OpenLayer(ctx, w.transport, w.Open)
+}
+
+// marks the layer open with already negotiated parameters and sequence numbers without snrm (resumed link, replays),
+// lower transport has to be opened by the caller, sizes have to be in the same range as New accepts
+func (w *maclayer) Adopt(maxSnd, maxRcv uint, controlS, controlR byte) error {
+	if maxSnd < 128 || maxSnd > initpacketlength {
+		return fmt.Errorf("invalid max send size %d", maxSnd)
+	}
+	if maxRcv < 128 || maxRcv > initpacketlength {
+		return fmt.Errorf("invalid max receive size %d", maxRcv)
+	}
+	w.addrlen = w.getaddresslength()
+	w.settings.MaxSnd = maxSnd
+	w.settings.MaxRcv = maxRcv
+	w.controlS = controlS & 7
+	w.controlR = controlR & 7
+	w.toreadout = false
+	w.toberead = nil
+	w.tobereadpacket = nil
+	w.segmentbytes = 0
+	w.draining = false
+	w.writeoffset = 0
+	w.redactframe = nil
+	w.flagonline = false
+	w.isopen = true
+	return nil
+}
+
 func (w *maclayer) parsesnrmua(ua []byte) error {
 	if ua == nil {
 		return fmt.Errorf("no ua response")
@@ -288,6 +352,7 @@ func readsnrmuatag(t []byte) (int, uint, error) {
 
 func (w *maclayer) Disconnect() error {
 	w.isopen = false // just hardcore
+	w.flagonline = false
 	return w.transport.Disconnect()
 }
 
@@ -303,6 +368,12 @@ func (w *maclayer) getnextI() (pck *macpacket, err error) {
 				return nil, fmt.Errorf("invalid unexpected packet numbering (SSS)")
 			}
 			w.controlR = (w.controlR + 1) & 7
+			w.segmentbytes += len(pck.info)
+			if !w.draining && w.settings.MaxResponseSize > 0 && w.segmentbytes > w.settings.MaxResponseSize {
+				pck.info = nil
+				w.tobereadpacket = pck
+				return nil, w.drainsegments()
+			}
 			return
 		} else if pck.control == 3 {
 			w.logf("received UI, discarding")
@@ -317,6 +388,20 @@ func (w *maclayer) getnextI() (pck *macpacket, err error) {
 	return nil, nil
 }
 
+// reads out and drops the rest of the response, so the link stays in sync, if even that fails, just disconnect
+func (w *maclayer) drainsegments() error {
+	w.logf("response exceeds %d bytes, dropping the rest", w.settings.MaxResponseSize)
+	w.draining = true
+	w.toreadout = true
+	err := w.readout()
+	w.draining = false
+	if err != nil {
+		_ = w.Disconnect()
+		return fmt.Errorf("%w, link disconnected after failed drain: %v", ErrSegmentTooLarge, err)
+	}
+	return ErrSegmentTooLarge
+}
+
 func (w *maclayer) sendRR() error {
 	return w.writepacket(macpacket{control: (w.controlR << 5) | 1, info: nil, segmented: false}, true)
 }
@@ -453,6 +538,7 @@ func (w *maclayer) writeout() error {
 		return nil
 	}
 	w.toreadout = true
+	w.segmentbytes = 0
 	return nil
 }
 
@@ -468,6 +554,9 @@ func (w *maclayer) Write(src []byte) error {
 	if err != nil {
 		return err
 	}
+	red := w.redactnext
+	w.redactnext = nil
+	off := 0
 	// fuck, as write is supposed to process everything, this has to be cycle
 	for len(src) > 0 {
 		l := len(src)
@@ -477,7 +566,9 @@ func (w *maclayer) Write(src []byte) error {
 			s = true
 		}
 		copy(w.sendbuffer[11+w.writeoffset:], src[:l]) // a bit hardcore, 11 is important constant ;)
+		w.redactframe = append(w.redactframe, base.ShiftRedactions(red, off, l, w.writeoffset)...)
 		w.writeoffset += l
+		off += l
 		if s { // send partial packet with segment bit
 			err = w.writepacket(macpacket{control: w.nextcontrol(), segmented: true}, true)
 			if err != nil {
@@ -509,6 +600,66 @@ func (w *maclayer) Write(src []byte) error {
 	return nil
 }
 
+// sends the last I frame held back by Write and waits for its RR, for requests without any answer
+func (w *maclayer) Flush() error {
+	if !w.isopen {
+		return base.ErrNotOpened
+	}
+	if w.writeoffset == 0 {
+		return nil
+	}
+	err := w.writeout()
+	if err != nil {
+		return err
+	}
+	w.toreadout = false // nothing comes back except RR
+	cnt := w.settings.Retransmits
+	for {
+		err = w.processRRresp()
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, base.ErrCommunicationTimeout) {
+			if cnt <= 0 {
+				return err
+			}
+			cnt--
+		} else {
+			return err
+		}
+		err = w.retransmit()
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// drops rest of the response being received, nothing is read (so nothing retransmitted) when no response is pending,
+// the whole drain is bounded by t
+func (w *maclayer) Drain(t time.Duration) error {
+	if !w.isopen {
+		return base.ErrNotOpened
+	}
+	w.SetDeadline(time.Now().Add(t))
+	defer w.SetDeadline(time.Time{})
+	w.writeoffset = 0 // request not sent yet is dropped
+	w.redactframe = nil
+	rt := w.settings.Retransmits
+	w.settings.Retransmits = 0 // timeout means there is nothing more to drain
+	err := w.readout()
+	w.settings.Retransmits = rt
+	w.toberead = nil
+	w.tobereadpacket = nil
+	if errors.Is(err, base.ErrCommunicationTimeout) {
+		return nil
+	}
+	return err
+}
+
+func (w *maclayer) RedactNextWrite(r []base.Redaction) {
+	w.redactnext = r
+}
+
 func (w *maclayer) readout() error {
 	if !w.toreadout {
 		return nil
@@ -553,6 +704,22 @@ func (w *maclayer) GetRxTxBytes() (int64, int64) {
 	return w.transport.GetRxTxBytes()
 }
 
+// per frame, two flags, format, addresses, control, hcs and fcs
+func (w *maclayer) OverheadBytes() int {
+	return 10 + w.getaddresslength() + base.OverheadBytes(w.transport)
+}
+
+// addresses and frame sizes, sizes are negotiated ones after Open
+func (w *maclayer) ConnectionInfo() map[string]string {
+	ret := base.ConnectionInfo(w.transport)
+	ret["hdlc.client"] = strconv.Itoa(int(w.settings.Client))
+	ret["hdlc.logical"] = strconv.Itoa(int(w.settings.Logical))
+	ret["hdlc.physical"] = strconv.Itoa(int(w.settings.Physical))
+	ret["hdlc.maxrcv"] = strconv.Itoa(int(w.settings.MaxRcv))
+	ret["hdlc.maxsnd"] = strconv.Itoa(int(w.settings.MaxSnd))
+	return ret
+}
+
 var fcstab = [...]uint16{
 	0x0000, 0x1189, 0x2312, 0x329b, 0x4624, 0x57ad, 0x6536, 0x74bf,
 	0x8c48, 0x9dc1, 0xaf5a, 0xbed3, 0xca6c, 0xdbe5, 0xe97e, 0xf8f7,
@@ -624,6 +791,10 @@ func (w *maclayer) readpackets() ([]macpacket, error) {
 		first = false
 		final = m.control&0x10 != 0
 		m.control &= 0xef // clear final bit
+		w.stats.count(m.control, false)
+		if m.control&1 == 0 && len(m.info) == 0 {
+			w.stats.EmptyIFrames++
+		}
 		w.packetsbuffer[off] = m
 		off++
 	}
@@ -644,6 +815,7 @@ func (w *maclayer) parseminheader() (uint, error) {
 func (w *maclayer) readpacket(first bool) (pck macpacket, err error) { // remove recursion and call it repeatedly from another caller and return array of packets
 	// 0 waiting for 0x7e and reading minimal header, 1 reading rest of the packet, 2 closing 0x7e (maybe not so necessary)
 	length := uint(0)
+	w.flagonline = false
 	if first {
 		bcnt := 0
 		for {
@@ -736,9 +908,10 @@ func (w *maclayer) parsepacket(ori []byte) (pck macpacket, err error) {
 	if ori[2]&1 == 0 {
 		return pck, fmt.Errorf("invalid ending bit of client address")
 	}
-	if ori[2]>>1 != w.settings.Client {
+	if ori[2]>>1 != w.settings.Client && !(w.settings.AcceptBroadcast && ori[2]>>1 == allstation) {
 		return pck, fmt.Errorf("invalid client address")
 	}
+	bcast := uint16(allstation)
 	offset := 0
 	var log uint16     // upper
 	var phy uint16     // lower
@@ -760,13 +933,20 @@ func (w *maclayer) parsepacket(ori []byte) (pck macpacket, err error) {
 		log = uint16(ori[3]>>1)<<7 | uint16(ori[4]>>1)
 		phy = uint16(ori[5]>>1)<<7 | uint16(ori[6]>>1)
 		offset = 4
+		bcast = allstation2
 	}
 
-	if log != w.settings.Logical {
-		return pck, fmt.Errorf("mismatch logical address")
-	}
-	if phy != w.settings.Physical {
-		return pck, fmt.Errorf("mismatch physical address")
+	if w.settings.AcceptBroadcast && log == bcast { // single byte broadcast has no physical part at all
+		if offset != 1 && phy != bcast && phy != w.settings.Physical {
+			return pck, fmt.Errorf("mismatch physical address")
+		}
+	} else {
+		if log != w.settings.Logical {
+			return pck, fmt.Errorf("mismatch logical address")
+		}
+		if phy != w.settings.Physical && !(w.settings.AcceptBroadcast && phy == bcast) {
+			return pck, fmt.Errorf("mismatch physical address")
+		}
 	}
 
 	if len(ori) < offset+6 {
@@ -785,6 +965,7 @@ func (w *maclayer) parsepacket(ori []byte) (pck macpacket, err error) {
 		// check FCS
 		fcs := mac_crc16(ori[:len(ori)-2])
 		if fcs != uint16(ori[len(ori)-2])|(uint16(ori[len(ori)-1])<<8) {
+			w.stats.CRCErrors++
 			return pck, fmt.Errorf("fcs mismatch")
 		}
 		return pck, nil
@@ -793,9 +974,11 @@ func (w *maclayer) parsepacket(ori []byte) (pck macpacket, err error) {
 	default: // having some info
 		hcs, fcs := mac_crc16_r(ori[:len(ori)-2], offset+1)
 		if hcs != uint16(ori[offset+1])|(uint16(ori[offset+2])<<8) {
+			w.stats.CRCErrors++
 			return pck, fmt.Errorf("hcs mismatch")
 		}
 		if fcs != uint16(ori[len(ori)-2])|(uint16(ori[len(ori)-1])<<8) {
+			w.stats.CRCErrors++
 			return pck, fmt.Errorf("fcs mismatch")
 		}
 		pck.info = ori[offset+3 : len(ori)-2] // dont copy, keep slice so wasting memory for crc and header
@@ -834,23 +1017,26 @@ func mac_crc16_w(d []byte, ih int) uint16 {
 
 func (w *maclayer) writepacket(packet macpacket, final bool) (err error) {
 	var pck []byte
+	var start int // of pck in sendbuffer
 	switch w.addrlen {
 	case 1:
 		w.sendbuffer[6] = byte(w.settings.Logical<<1) | 1
-		pck = w.sendbuffer[3:]
+		start = 3
 	case 2:
 		w.sendbuffer[5] = byte(w.settings.Logical << 1)
 		w.sendbuffer[6] = byte(w.settings.Physical<<1) | 1
-		pck = w.sendbuffer[2:]
+		start = 2
 	case 4:
 		w.sendbuffer[3] = byte(w.settings.Logical>>7) << 1
 		w.sendbuffer[4] = byte(w.settings.Logical << 1)
 		w.sendbuffer[5] = byte(w.settings.Physical>>7) << 1
 		w.sendbuffer[6] = byte(w.settings.Physical<<1) | 1
-		pck = w.sendbuffer[:]
+		start = 0
 	default:
 		return fmt.Errorf("invalid address length, programatic error")
 	}
+	pck = w.sendbuffer[start:]
+	w.lastredact = nil
 
 	pck[0] = 0x7e
 	offset := 3 + w.addrlen // address + header + 0x7e
@@ -877,6 +1063,8 @@ func (w *maclayer) writepacket(packet macpacket, final bool) (err error) {
 		offset++
 		pck[offset] = byte(fcs >> 8)
 		offset++
+		w.lastredact = base.ShiftRedactions(w.redactframe, 0, w.writeoffset, 11-start)
+		w.redactframe = nil
 		w.writeoffset = 0
 	} else if len(packet.info) > 0 {
 		leni := offset + 3 + len(packet.info)
@@ -912,5 +1100,14 @@ func (w *maclayer) writepacket(packet macpacket, final bool) (err error) {
 	offset++
 
 	w.lastsend = pck[:offset]
-	return w.transport.Write(pck[:offset])
+	w.stats.count(packet.control, true)
+	if w.settings.DoubleFlag && w.flagonline {
+		pck = pck[1:offset]
+		base.RedactNextWrite(w.transport, base.ShiftRedactions(w.lastredact, 1, offset-1, 0))
+	} else {
+		pck = pck[:offset]
+		base.RedactNextWrite(w.transport, w.lastredact)
+	}
+	w.flagonline = true
+	return w.transport.Write(pck)
 }
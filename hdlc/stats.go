@@ -0,0 +1,79 @@
+package hdlc
+
+import (
+	"time"
+
+	"github.com/cybroslabs/libdlms-go/base"
+)
+
+// link quality counters, they are kept over reconnects till ResetStats
+type Stats struct {
+	IFramesSent     uint64
+	IFramesReceived uint64
+	RRSent          uint64
+	RRReceived      uint64
+	RNRSent         uint64
+	RNRReceived     uint64
+	UISent          uint64
+	UIReceived      uint64
+	CRCErrors       uint64 // hcs or fcs mismatch
+	Retransmits     uint64
+	EmptyIFrames    uint64 // received I frames without any info
+	OtherSent       uint64 // snrm, disc, ...
+	OtherReceived   uint64 // ua, dm, frmr, ...
+}
+
+type HdlcStream interface {
+	base.Stream
+	Stats() Stats
+	ResetStats()
+	Adopt(maxSnd, maxRcv uint, controlS, controlR byte) error
+	Flush() error
+	Drain(t time.Duration) error
+}
+
+func (w *maclayer) Stats() Stats {
+	return w.stats
+}
+
+func (w *maclayer) ResetStats() {
+	w.stats = Stats{}
+}
+
+// control without final bit
+func (s *Stats) count(control byte, sent bool) {
+	var c *uint64
+	switch {
+	case control&1 == 0:
+		if sent {
+			c = &s.IFramesSent
+		} else {
+			c = &s.IFramesReceived
+		}
+	case control&0xf == 1:
+		if sent {
+			c = &s.RRSent
+		} else {
+			c = &s.RRReceived
+		}
+	case control&0xf == 5:
+		if sent {
+			c = &s.RNRSent
+		} else {
+			c = &s.RNRReceived
+		}
+	case control == 3:
+		if sent {
+			c = &s.UISent
+		} else {
+			c = &s.UIReceived
+		}
+	default:
+		if sent {
+			c = &s.OtherSent
+		} else {
+			c = &s.OtherReceived
+		}
+	}
+	*c++
+}
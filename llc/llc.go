@@ -1,6 +1,7 @@
 package llc
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"time"
@@ -14,6 +15,8 @@ type llc struct {
 	logger    *zap.SugaredLogger
 	header    []byte
 	state     int // 0 - start, 1 - writting, 2 - reading
+
+	redactions []base.Redaction // of the next write only, header isnt counted
 }
 
 // Close implements base.Stream.
@@ -31,6 +34,10 @@ func (l *llc) Open() error {
 	return l.transport.Open()
 }
 
+func (l *llc) OpenContext(ctx context.Context) error {
+	return base.OpenLayer(ctx, l.transport, l.Open)
+}
+
 // Receive implements base.Stream.
 func (l *llc) Read(p []byte) (n int, err error) {
 	if l.state == 2 {
@@ -49,7 +56,10 @@ func (l *llc) Read(p []byte) (n int, err error) {
 
 // Send implements base.Stream.
 func (l *llc) Write(src []byte) error { // always write everything
+	red := l.redactions
+	l.redactions = nil
 	if l.state == 1 {
+		base.RedactNextWrite(l.transport, red)
 		return l.transport.Write(src)
 	}
 	l.state = 1
@@ -60,9 +70,14 @@ func (l *llc) Write(src []byte) error { // always write everything
 	if err != nil {
 		return err
 	}
+	base.RedactNextWrite(l.transport, red)
 	return l.transport.Write(src)
 }
 
+func (l *llc) RedactNextWrite(r []base.Redaction) {
+	l.redactions = r
+}
+
 func (l *llc) SetMaxReceivedBytes(m int64) {
 	l.transport.SetMaxReceivedBytes(m)
 }
@@ -85,6 +100,25 @@ func (l *llc) GetRxTxBytes() (int64, int64) {
 	return l.transport.GetRxTxBytes()
 }
 
+func (l *llc) OverheadBytes() int {
+	return len(l.header) + base.OverheadBytes(l.transport)
+}
+
+// apdu without answer is complete, so the next write starts a new one with its own header
+func (l *llc) Flush() error {
+	l.state = 0
+	return base.Flush(l.transport)
+}
+
+func (l *llc) Drain(t time.Duration) error {
+	l.state = 0
+	return base.Drain(l.transport, t)
+}
+
+func (l *llc) ConnectionInfo() map[string]string {
+	return base.ConnectionInfo(l.transport)
+}
+
 func New(transport base.Stream) base.Stream {
 	return &llc{
 		transport: transport,
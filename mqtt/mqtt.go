@@ -0,0 +1,258 @@
+package mqtt
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cybroslabs/libdlms-go/base"
+	"go.uber.org/zap"
+)
+
+const (
+	maxQueuedMessages = 16
+)
+
+type Message struct {
+	Topic         string
+	CorrelationID []byte // mqtt5 correlation data or whatever the gateway uses
+	Payload       []byte
+}
+
+// minimal client, wrap your broker library of choice, connection to the broker is handled outside, so one client can serve many streams
+type Client interface {
+	Publish(msg *Message) error
+	// handler can be called from any goroutine
+	Subscribe(topic string, handler func(msg *Message)) error
+	Unsubscribe(topic string) error
+}
+
+type mqtt struct {
+	client          Client
+	requesttopic    string
+	responsetopic   string
+	logger          *zap.SugaredLogger
+	connected       bool
+	timeout         time.Duration
+	deadline        time.Time
+	prefix          [8]byte
+	counter         uint32
+	mutex           sync.Mutex
+	correlation     []byte // of the last request, only matching responses are accepted
+	incoming        chan []byte
+	buffer          []byte
+	totalincoming   int64
+	totaloutgoing   int64
+	currentincoming int64
+	maxincoming     int64
+
+	redactions []base.Redaction // of the next write only
+}
+
+// every Write is published as a single message, so the whole apdu (or wrapper frame) has to be written at once, which dlmsal does
+func New(client Client, requestTopic string, responseTopic string, timeout time.Duration) base.Stream {
+	m := &mqtt{
+		client:        client,
+		requesttopic:  requestTopic,
+		responsetopic: responseTopic,
+		timeout:       timeout,
+	}
+	_, _ = rand.Read(m.prefix[:]) // in case of error just less unique prefix, counter is still there
+	return m
+}
+
+func (w *mqtt) logf(format string, v ...any) {
+	if w.logger != nil {
+		w.logger.Infof(format, v...)
+	}
+}
+
+func (w *mqtt) Close() error {
+	return nil // no association at this level
+}
+
+func (w *mqtt) Open() error {
+	if w.connected {
+		return nil
+	}
+	if w.client == nil {
+		return fmt.Errorf("no mqtt client")
+	}
+	w.mutex.Lock()
+	w.incoming = make(chan []byte, maxQueuedMessages)
+	w.correlation = nil
+	w.mutex.Unlock()
+	w.buffer = nil
+	if err := w.client.Subscribe(w.responsetopic, w.handler); err != nil {
+		return fmt.Errorf("subscribe failed: %w", err)
+	}
+	w.connected = true
+	w.logf("Subscribed to %s", w.responsetopic)
+	return nil
+}
+
+func (w *mqtt) OpenContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d, ok := ctx.Deadline(); ok {
+		w.deadline = d
+	}
+	return w.Open()
+}
+
+func (w *mqtt) Disconnect() error {
+	if !w.connected {
+		return nil
+	}
+	w.connected = false
+	w.mutex.Lock()
+	w.correlation = nil // late responses are dropped from now
+	w.mutex.Unlock()
+	w.logf("Unsubscribed from %s", w.responsetopic)
+	w.logf("Total bytes incoming: %v, outgoing: %v", w.totalincoming, w.totaloutgoing)
+	return w.client.Unsubscribe(w.responsetopic)
+}
+
+func (w *mqtt) handler(msg *Message) {
+	if msg == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.correlation == nil || !bytes.Equal(w.correlation, msg.CorrelationID) {
+		w.logf("Dropping uncorrelated message on %s", msg.Topic)
+		return
+	}
+	select {
+	case w.incoming <- msg.Payload:
+	default:
+		w.logf("Dropping message on %s, queue is full", msg.Topic)
+	}
+}
+
+func (w *mqtt) SetLogger(logger *zap.SugaredLogger) {
+	w.logger = logger
+}
+
+func (w *mqtt) SetDeadline(t time.Time) {
+	w.deadline = t
+}
+
+func (w *mqtt) SetTimeout(t time.Duration) {
+	w.timeout = t
+}
+
+func (w *mqtt) SetMaxReceivedBytes(m int64) {
+	w.currentincoming = 0
+	w.maxincoming = m
+}
+
+func (w *mqtt) RedactNextWrite(r []base.Redaction) {
+	w.redactions = r
+}
+
+func (w *mqtt) Write(src []byte) error {
+	if !w.connected {
+		return base.ErrNotOpened
+	}
+	red := w.redactions
+	w.redactions = nil
+	w.counter++
+	corr := make([]byte, len(w.prefix)+4)
+	copy(corr, w.prefix[:])
+	binary.BigEndian.PutUint32(corr[len(w.prefix):], w.counter)
+
+	w.mutex.Lock()
+	w.correlation = corr
+	for len(w.incoming) > 0 { // whatever is queued belongs to the previous request
+		<-w.incoming
+	}
+	w.mutex.Unlock()
+	w.buffer = nil
+
+	if w.logger != nil {
+		w.logger.Debugf(base.LogHexRedacted("TX", src, red))
+	}
+	err := w.client.Publish(&Message{Topic: w.requesttopic, CorrelationID: corr, Payload: src})
+	if err != nil {
+		return fmt.Errorf("publish failed: %w", err)
+	}
+	w.totaloutgoing += int64(len(src))
+	return nil
+}
+
+func (w *mqtt) Read(p []byte) (int, error) {
+	if !w.connected {
+		return 0, base.ErrNotOpened
+	}
+	if len(p) == 0 {
+		return 0, base.ErrNothingToRead
+	}
+
+	for len(w.buffer) == 0 {
+		var tc <-chan time.Time
+		var t *time.Timer
+		if d := w.waittime(); d > 0 {
+			t = time.NewTimer(d)
+			tc = t.C
+		} else if d < 0 {
+			return 0, base.ErrCommunicationTimeout
+		}
+		var b []byte
+		received := false
+		select {
+		case b = <-w.incoming:
+			received = true
+		case <-tc:
+		}
+		if t != nil {
+			t.Stop()
+		}
+		if !received {
+			return 0, base.ErrCommunicationTimeout
+		}
+		w.totalincoming += int64(len(b))
+		w.currentincoming += int64(len(b))
+		if w.maxincoming > 0 && w.currentincoming > w.maxincoming {
+			return 0, fmt.Errorf("received more than allowed")
+		}
+		if w.logger != nil {
+			w.logger.Debugf(base.LogHex("RX", b))
+		}
+		w.buffer = b
+	}
+	n := copy(p, w.buffer)
+	w.buffer = w.buffer[n:]
+	return n, nil
+}
+
+// zero means wait forever, negative means already expired
+func (w *mqtt) waittime() time.Duration {
+	d := w.timeout
+	if !w.deadline.IsZero() {
+		dd := time.Until(w.deadline)
+		if dd <= 0 {
+			return -1
+		}
+		if d == 0 || dd < d {
+			d = dd
+		}
+	}
+	return d
+}
+
+func (w *mqtt) GetRxTxBytes() (int64, int64) {
+	return w.totalincoming, w.totaloutgoing
+}
+
+func (w *mqtt) ConnectionInfo() map[string]string {
+	return map[string]string{
+		"mqtt.requesttopic":  w.requesttopic,
+		"mqtt.responsetopic": w.responsetopic,
+	}
+}
@@ -1,9 +1,11 @@
 package rfc2217
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"time"
 
@@ -34,6 +36,7 @@ type rfc2217Serial struct {
 	transport   base.Stream // usually tcp
 	isopen      bool
 	writebuffer []byte
+	redactions  []base.Redaction // of the next write only
 
 	settings base.SerialStreamSettings
 
@@ -66,6 +69,26 @@ func (r *rfc2217Serial) GetRxTxBytes() (int64, int64) {
 	return r.transport.GetRxTxBytes()
 }
 
+func (r *rfc2217Serial) OverheadBytes() int {
+	return base.OverheadBytes(r.transport)
+}
+
+// line settings as last requested, rfc2217 server doesnt have to accept them
+func (r *rfc2217Serial) ConnectionInfo() map[string]string {
+	ret := base.ConnectionInfo(r.transport)
+	ret["serial.baudrate"] = strconv.Itoa(r.settings.BaudRate)
+	ret["serial.databits"] = fmt.Sprint(r.settings.DataBits)
+	ret["serial.parity"] = fmt.Sprint(r.settings.Parity)
+	ret["serial.stopbits"] = fmt.Sprint(r.settings.StopBits)
+	ret["serial.flowcontrol"] = fmt.Sprint(r.settings.FlowControl)
+	return ret
+}
+
+// OpenContext implements SerialStream.
+func (r *rfc2217Serial) OpenContext(ctx context.Context) error {
+	return base.OpenLayer(ctx, r.transport, r.Open)
+}
+
 // Open implements SerialStream.
 func (r *rfc2217Serial) Open() error {
 	if r.isopen {
@@ -261,6 +284,9 @@ func (r *rfc2217Serial) processSubnegotiation(sub []byte) error {
 		}
 		baudrate := int(binary.BigEndian.Uint32(sub[1:]))
 		r.logf("reported baudrate: %d", baudrate)
+		if baudrate != 0 { // zero is just a query
+			r.settings.BaudRate = baudrate
+		}
 	case 102: // set data bits
 		if len(sub) != 2 {
 			return fmt.Errorf("invalid subnegotiation length")
@@ -272,6 +298,7 @@ func (r *rfc2217Serial) processSubnegotiation(sub []byte) error {
 		}
 		databits := base.SerialDataBits(sub[1])
 		r.logf("reported data bits: %v", databits)
+		r.settings.DataBits = databits
 	case 103: // set parity
 		if len(sub) != 2 {
 			return fmt.Errorf("invalid subnegotiation length")
@@ -283,6 +310,7 @@ func (r *rfc2217Serial) processSubnegotiation(sub []byte) error {
 		}
 		parity := base.SerialParity(sub[1])
 		r.logf("reported parity: %v", parity)
+		r.settings.Parity = parity
 	case 104: // set stop bits
 		if len(sub) != 2 {
 			return fmt.Errorf("invalid subnegotiation length")
@@ -294,6 +322,7 @@ func (r *rfc2217Serial) processSubnegotiation(sub []byte) error {
 		}
 		stopbits := base.SerialStopBits(sub[1])
 		r.logf("reported stop bits: %v", stopbits)
+		r.settings.StopBits = stopbits
 	case 105: // set control
 		if len(sub) != 2 {
 			return fmt.Errorf("invalid subnegotiation length")
@@ -302,6 +331,7 @@ func (r *rfc2217Serial) processSubnegotiation(sub []byte) error {
 		case base.SerialNoFlowControl, base.SerialSWFlowControl, base.SerialHWFlowControl, base.SerialDCDFlowControl, base.SerialDSRFlowControl:
 			control := base.SerialFlowControl(sub[1])
 			r.logf("reported control: %v", control)
+			r.settings.FlowControl = control
 		default:
 			r.logf("unsupported control %02x", sub[1])
 		}
@@ -407,6 +437,43 @@ func (r *rfc2217Serial) SetDTR(dtr bool) error {
 	return r.transport.Write(r.writebuffer)
 }
 
+// SetRTS implements SerialStream.
+func (r *rfc2217Serial) SetRTS(rts bool) error {
+	if !r.isopen {
+		return base.ErrNotOpened
+	}
+
+	setrts := byte(11)
+	if !rts {
+		setrts = 12
+	}
+	r.writebuffer = r.writeSubnegotiation(r.writebuffer[:0], 5, []byte{setrts})
+	return r.transport.Write(r.writebuffer)
+}
+
+// PulseDTR implements SerialStream.
+func (r *rfc2217Serial) PulseDTR(d time.Duration) error {
+	return pulse(r.SetDTR, d)
+}
+
+// PulseRTS implements SerialStream.
+func (r *rfc2217Serial) PulseRTS(d time.Duration) error {
+	return pulse(r.SetRTS, d)
+}
+
+func pulse(set func(bool) error, d time.Duration) error {
+	if err := set(true); err != nil {
+		return err
+	}
+	time.Sleep(d)
+	return set(false)
+}
+
+// CurrentSettings implements SerialStream.
+func (r *rfc2217Serial) CurrentSettings() base.SerialStreamSettings {
+	return r.settings
+}
+
 // SetFlowControl implements SerialStream.
 func (r *rfc2217Serial) SetFlowControl(flowControl base.SerialFlowControl) error {
 	if !r.isopen {
@@ -495,23 +562,44 @@ func (r *rfc2217Serial) Write(src []byte) error {
 	if len(src) == 0 {
 		return nil
 	}
+	red := r.redactions
+	r.redactions = nil
+	var chunkred []base.Redaction // of the current chunk, escaping included
 	// escape IAC bytes, limit size and chunk that thing, even not optimally due to lower MTU?
 	r.writebuffer = r.writebuffer[:0]
-	for _, b := range src {
+	for i, b := range src {
 		if len(r.writebuffer) >= writeChunk {
+			base.RedactNextWrite(r.transport, chunkred)
+			chunkred = nil
 			if err := r.transport.Write(r.writebuffer); err != nil {
 				return err
 			}
 			r.writebuffer = r.writebuffer[:0]
 		}
+		if len(red) > 0 && len(base.ShiftRedactions(red, i, 1, 0)) > 0 {
+			n := 1
+			if b == IAC {
+				n = 2
+			}
+			if l := len(chunkred) - 1; l >= 0 && chunkred[l].Offset+chunkred[l].Length == len(r.writebuffer) {
+				chunkred[l].Length += n
+			} else {
+				chunkred = append(chunkred, base.Redaction{Offset: len(r.writebuffer), Length: n})
+			}
+		}
 		if b == IAC {
 			r.writebuffer = append(r.writebuffer, IAC)
 		}
 		r.writebuffer = append(r.writebuffer, b)
 	}
+	base.RedactNextWrite(r.transport, chunkred)
 	return r.transport.Write(r.writebuffer)
 }
 
+func (r *rfc2217Serial) RedactNextWrite(red []base.Redaction) {
+	r.redactions = red
+}
+
 func New(t base.Stream, settings *base.SerialStreamSettings) base.SerialStream {
 	ret := &rfc2217Serial{
 		settings:    *settings,
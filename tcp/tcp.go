@@ -1,6 +1,7 @@
 package tcp
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -29,9 +30,29 @@ type tcp struct {
 	currentincoming int64
 	maxincoming     int64
 	inerror         error
+	keepalive       time.Duration
+
+	ip         net.IP   // pre-resolved address, dns is skipped completely
+	resolved   []net.IP // cached dns result
+	resolvedat time.Time
+	dnsttl     time.Duration
+
+	redactions []base.Redaction // of the next write only
+}
+
+// how long resolved addresses of hostname are reused between opens by default
+const DefaultDNSCacheTTL = time.Minute
+
+type TcpStream interface {
+	base.Stream
+	// tcp keepalive probes interval (also idle time before the first one), dead peer behind nat is then detected by the os and read fails
+	// instead of waiting for the timeout, zero means go default, negative disables keepalive
+	SetTCPKeepAlive(interval time.Duration)
+	// how long resolved addresses are reused for next opens, zero resolves on every open, cache is dropped also when connect fails
+	SetDNSCacheTTL(ttl time.Duration)
 }
 
-func New(hostname string, port int, timeout time.Duration) base.Stream {
+func New(hostname string, port int, timeout time.Duration) TcpStream {
 	return &tcp{
 		hostname:        hostname,
 		port:            port,
@@ -47,9 +68,17 @@ func New(hostname string, port int, timeout time.Duration) base.Stream {
 		totaloutgoing:   0,
 		currentincoming: 0,
 		maxincoming:     0,
+		dnsttl:          DefaultDNSCacheTTL,
 	}
 }
 
+// the same as New, but connects to already resolved address, so no dns lookup happens at all
+func NewResolved(ip net.IP, port int, timeout time.Duration) TcpStream {
+	ret := New(ip.String(), port, timeout).(*tcp)
+	ret.ip = ip
+	return ret
+}
+
 func (w *tcp) logf(format string, v ...any) {
 	if w.logger != nil {
 		w.logger.Infof(format, v...)
@@ -61,17 +90,44 @@ func (t *tcp) Close() error {
 }
 
 func (t *tcp) Open() error {
-	if !t.connected {
-		address := net.JoinHostPort(t.hostname, strconv.Itoa(t.port))
+	return t.open(context.Background())
+}
+
+func (t *tcp) OpenContext(ctx context.Context) error {
+	if d, ok := ctx.Deadline(); ok {
+		t.deadline = d
+	}
+	return t.open(ctx)
+}
 
-		conn, err := net.DialTimeout("tcp", address, t.timeout)
+func (t *tcp) open(ctx context.Context) error {
+	if !t.connected {
+		ips, err := t.resolve(ctx)
 		if err != nil {
-			t.logf("Connect to %s failed: %v", address, err.Error())
+			t.logf("Resolve of %s failed: %v", t.hostname, err.Error())
 
 			return fmt.Errorf("connect failed: %w", err)
 		}
 
-		t.logf("Connected to %s", address)
+		dialer := net.Dialer{Timeout: t.timeout, KeepAlive: t.keepalive}
+		var conn net.Conn
+		for _, ip := range ips { // in order as resolver returned them, the same as dialer does it
+			address := net.JoinHostPort(ip.String(), strconv.Itoa(t.port))
+			conn, err = dialer.DialContext(ctx, "tcp", address)
+			if err == nil {
+				t.logf("Connected to %s (%s)", t.hostname, address)
+				break
+			}
+			t.logf("Connect to %s (%s) failed: %v", t.hostname, address, err.Error())
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		if err != nil {
+			t.resolved = nil // address could change, resolve again next time
+
+			return fmt.Errorf("connect failed: %w", err)
+		}
 
 		t.conn = conn
 		t.connected = true
@@ -79,6 +135,40 @@ func (t *tcp) Open() error {
 	return nil
 }
 
+func (t *tcp) resolve(ctx context.Context) ([]net.IP, error) {
+	if t.ip != nil {
+		return []net.IP{t.ip}, nil
+	}
+	if ip := net.ParseIP(t.hostname); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	if len(t.resolved) > 0 && t.dnsttl > 0 && time.Since(t.resolvedat) < t.dnsttl {
+		return t.resolved, nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, t.hostname)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no address found for %s", t.hostname)
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, a := range addrs {
+		ips[i] = a.IP
+	}
+	t.resolved = ips
+	t.resolvedat = time.Now()
+	return ips, nil
+}
+
+func (t *tcp) SetDNSCacheTTL(ttl time.Duration) {
+	t.dnsttl = ttl
+	if ttl <= 0 {
+		t.resolved = nil
+	}
+}
+
 func (t *tcp) Disconnect() error {
 	if t.connected {
 		t.connected = false
@@ -95,6 +185,23 @@ func (t *tcp) Disconnect() error {
 	return nil
 }
 
+func (t *tcp) SetTCPKeepAlive(interval time.Duration) {
+	t.keepalive = interval
+	if !t.connected {
+		return // dialer uses it
+	}
+	if tc, ok := t.conn.(*net.TCPConn); ok {
+		if interval < 0 {
+			_ = tc.SetKeepAlive(false)
+			return
+		}
+		_ = tc.SetKeepAlive(true)
+		if interval > 0 {
+			_ = tc.SetKeepAlivePeriod(interval)
+		}
+	}
+}
+
 func (t *tcp) SetMaxReceivedBytes(m int64) {
 	t.currentincoming = 0
 	t.maxincoming = m
@@ -135,11 +242,18 @@ func (t *tcp) setcommdeadline() { // yes, this is shit
 	}
 }
 
+func (t *tcp) RedactNextWrite(r []base.Redaction) {
+	t.redactions = r
+}
+
 func (t *tcp) Write(src []byte) error {
 	if !t.connected {
 		return base.ErrNotOpened
 	}
 
+	red := t.redactions
+	t.redactions = nil
+	off := 0
 	for len(src) > 0 {
 		t.setcommdeadline()
 		n, err := t.conn.Write(src) // does that fulfill io.Writer interface so it returns not nil err even there is less written bytes?
@@ -149,9 +263,10 @@ func (t *tcp) Write(src []byte) error {
 		t.totaloutgoing += int64(n)
 
 		if t.logger != nil {
-			t.logger.Debugf(base.LogHex("TX", src[:n]))
+			t.logger.Debugf(base.LogHexRedacted("TX", src[:n], base.ShiftRedactions(red, off, n, 0)))
 		}
 
+		off += n
 		src = src[n:]
 	}
 
@@ -216,3 +331,15 @@ func (t *tcp) Read(p []byte) (int, error) {
 func (t *tcp) GetRxTxBytes() (int64, int64) {
 	return t.totalincoming, t.totaloutgoing
 }
+
+func (t *tcp) ConnectionInfo() map[string]string {
+	ret := map[string]string{
+		"tcp.host": t.hostname,
+		"tcp.port": strconv.Itoa(t.port),
+	}
+	if t.connected && t.conn != nil {
+		ret["tcp.remote"] = t.conn.RemoteAddr().String()
+		ret["tcp.local"] = t.conn.LocalAddr().String()
+	}
+	return ret
+}
@@ -1,8 +1,10 @@
 package wrapper
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/cybroslabs/libdlms-go/base"
@@ -18,6 +20,9 @@ type wrapper struct {
 	remaining   int
 	expresp     bool
 	towrite     int
+
+	redactnext []base.Redaction // of the next write
+	redactions []base.Redaction // of the buffered packet, header included
 }
 
 func (w *wrapper) logf(format string, v ...any) {
@@ -52,6 +57,10 @@ func (w *wrapper) Open() error {
 	return w.transport.Open()
 }
 
+func (w *wrapper) OpenContext(ctx context.Context) error {
+	return base.OpenLayer(ctx, w.transport, w.Open)
+}
+
 func (w *wrapper) SetMaxReceivedBytes(m int64) {
 	w.transport.SetMaxReceivedBytes(m)
 }
@@ -105,6 +114,8 @@ func (w *wrapper) Write(src []byte) error {
 	}
 
 	copy(w.buffer[w.towrite:], src)
+	w.redactions = append(w.redactions, base.ShiftRedactions(w.redactnext, 0, len(src), w.towrite)...)
+	w.redactnext = nil
 	w.towrite += len(src)
 	w.expresp = true
 	return nil
@@ -117,6 +128,8 @@ func (w *wrapper) flush() error {
 
 	w.buffer[6] = byte((w.towrite - 8) >> 8)
 	w.buffer[7] = byte(w.towrite - 8)
+	base.RedactNextWrite(w.transport, w.redactions)
+	w.redactions = nil
 	err := w.transport.Write(w.buffer[:w.towrite])
 	if err != nil {
 		return err
@@ -126,6 +139,22 @@ func (w *wrapper) flush() error {
 	return nil
 }
 
+func (w *wrapper) RedactNextWrite(r []base.Redaction) {
+	w.redactnext = r
+}
+
+// sends buffered packet right now, for requests without any answer
+func (w *wrapper) Flush() error {
+	if w.towrite == 0 {
+		return nil
+	}
+	w.expresp = false
+	if err := w.flush(); err != nil {
+		return err
+	}
+	return base.Flush(w.transport)
+}
+
 func (w *wrapper) Read(p []byte) (n int, err error) {
 	if w.expresp {
 		err = w.flush()
@@ -170,3 +199,14 @@ func (w *wrapper) Read(p []byte) (n int, err error) {
 func (w *wrapper) GetRxTxBytes() (int64, int64) {
 	return w.transport.GetRxTxBytes()
 }
+
+func (w *wrapper) OverheadBytes() int {
+	return 8 + base.OverheadBytes(w.transport) // version, source, destination and length
+}
+
+func (w *wrapper) ConnectionInfo() map[string]string {
+	ret := base.ConnectionInfo(w.transport)
+	ret["wrapper.source"] = strconv.Itoa(int(w.source))
+	ret["wrapper.destination"] = strconv.Itoa(int(w.destination))
+	return ret
+}